@@ -0,0 +1,105 @@
+package mimeparse
+
+import (
+	"strings"
+)
+
+// Deprecated or unofficial type names mapped to the names
+// that should be used instead.
+var aliases = map[string]string{
+	"text/json":                    "application/json",
+	"text/x-json":                  "application/json",
+	"application/x-json":           "application/json",
+	"application/javascript":       "text/javascript",
+	"application/x-javascript":     "text/javascript",
+	"application/ecmascript":       "text/javascript",
+	"application/x-ecmascript":     "text/javascript",
+	"text/ecmascript":              "text/javascript",
+	"text/x-javascript":            "text/javascript",
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/x-png":                  "image/png",
+	"image/x-icon":                 "image/vnd.microsoft.icon",
+	"image/svg":                    "image/svg+xml",
+	"audio/x-wav":                  "audio/wav",
+	"audio/wave":                   "audio/wav",
+	"audio/mp3":                    "audio/mpeg",
+	"audio/x-mp3":                  "audio/mpeg",
+	"audio/mpeg3":                  "audio/mpeg",
+	"application/x-gzip":           "application/gzip",
+	"application/x-zip-compressed": "application/zip",
+	"application/x-pdf":            "application/pdf",
+	"application/x-yaml":           "application/yaml",
+	"text/yaml":                    "application/yaml",
+	"text/x-yaml":                  "application/yaml",
+	"text/x-markdown":              "text/markdown",
+	"application/font-woff":        "font/woff",
+	"application/x-font-woff":      "font/woff",
+	"application/x-font-ttf":       "font/ttf",
+	"application/x-font-otf":       "font/otf",
+}
+
+// Replaces a deprecated or unofficial type name with the name that
+// should be used instead, keeping any parameters. Types that are
+// not aliases are returned unchanged. For example:
+//
+// Normalize(ParseMimeType("image/jpg;q=0.5"))
+// Mime {'image', 'jpeg', {'q', '0.5'}}
+func Normalize(mime Mime) Mime {
+	name, ok := aliases[mime.mtype+"/"+mime.subtype]
+	if !ok {
+		return mime
+	}
+	n := mime.Clone()
+	i := strings.Index(name, "/")
+	n.mtype, n.subtype = name[:i], name[i+1:]
+	return n
+}
+
+// Common aliases of charsets mapped to their IANA names, all in
+// lower case.
+var charsetAliases = map[string]string{
+	"utf8":              "utf-8",
+	"unicode-1-1-utf-8": "utf-8",
+	"utf16":             "utf-16",
+	"utf16le":           "utf-16le",
+	"utf16be":           "utf-16be",
+	"ascii":             "us-ascii",
+	"usascii":           "us-ascii",
+	"us_ascii":          "us-ascii",
+	"us":                "us-ascii",
+	"iso646-us":         "us-ascii",
+	"ansi_x3.4-1968":    "us-ascii",
+	"latin1":            "iso-8859-1",
+	"latin-1":           "iso-8859-1",
+	"l1":                "iso-8859-1",
+	"iso8859-1":         "iso-8859-1",
+	"iso_8859-1":        "iso-8859-1",
+	"iso88591":          "iso-8859-1",
+	"cp819":             "iso-8859-1",
+	"latin9":            "iso-8859-15",
+	"iso8859-15":        "iso-8859-15",
+	"iso_8859-15":       "iso-8859-15",
+	"cp1252":            "windows-1252",
+	"win-1252":          "windows-1252",
+	"sjis":              "shift_jis",
+	"shift-jis":         "shift_jis",
+	"eucjp":             "euc-jp",
+	"koi8r":             "koi8-r",
+}
+
+// Returns the IANA name of a charset in lower case, replacing common
+// aliases, so that charsets can be compared as strings. Names that
+// are not aliases are only lower-cased. For example:
+//
+// NormalizeCharset('UTF8')
+// 'utf-8'
+// NormalizeCharset('Latin1')
+// 'iso-8859-1'
+func NormalizeCharset(charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if name, ok := charsetAliases[charset]; ok {
+		return name
+	}
+	return charset
+}
@@ -0,0 +1,93 @@
+package mimeparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cond := map[string]string{
+		"text/json":                "application/json",
+		"application/x-javascript": "text/javascript",
+		"Image/JPG":                "image/jpeg",
+		"image/jpeg":               "image/jpeg",
+		"text/html":                "text/html",
+	}
+	for in, out := range cond {
+		m, _ := ParseMimeType(in)
+		n := Normalize(m)
+		if n.mtype+"/"+n.subtype != out {
+			t.Errorf("Normalize(%s) == %s/%s, not %s", in, n.mtype, n.subtype, out)
+		}
+	}
+	m, _ := ParseMediaRange("image/jpg;q=0.5")
+	n := Normalize(m)
+	if !reflect.DeepEqual(n.params.toMap(), map[string]string{"q": "0.5"}) {
+		t.Errorf("Normalize lost parameters, got %v", n.params.toMap())
+	}
+	if m.subtype != "jpg" {
+		t.Errorf("Normalize modified its argument")
+	}
+}
+
+func TestAliasTable(t *testing.T) {
+	for alias, name := range aliases {
+		if alias != strings.ToLower(alias) || strings.Count(name, "/") != 1 {
+			t.Errorf("Bad alias entry %s: %s", alias, name)
+		}
+		if _, ok := aliases[name]; ok {
+			t.Errorf("Alias %s maps to another alias %s", alias, name)
+		}
+	}
+}
+
+func TestNormalizeCharset(t *testing.T) {
+	cond := map[string]string{
+		"UTF8":         "utf-8",
+		"utf-8":        "utf-8",
+		" Latin1 ":     "iso-8859-1",
+		"iso8859-1":    "iso-8859-1",
+		"US-ASCII":     "us-ascii",
+		"ascii":        "us-ascii",
+		"Windows-1252": "windows-1252",
+	}
+	for charset, expected := range cond {
+		if result := NormalizeCharset(charset); result != expected {
+			t.Errorf("NormalizeCharset(%s) == %s, not %s", charset, result, expected)
+		}
+	}
+	for alias, name := range charsetAliases {
+		if alias != strings.ToLower(alias) || name != strings.ToLower(name) {
+			t.Errorf("Bad charset alias entry %s: %s", alias, name)
+		}
+		if _, ok := charsetAliases[name]; ok {
+			t.Errorf("Charset alias %s maps to another alias %s", alias, name)
+		}
+	}
+	if BestMatch([]string{"text/html;charset=utf-8"}, "text/html;charset=UTF8") == "" {
+		t.Errorf("Charset aliases should match")
+	}
+}
+
+func TestNormalizeAliasesPolicy(t *testing.T) {
+	p := &Policy{NormalizeAliases: true}
+	supported := []string{"application/json", "image/jpeg"}
+	headers := map[string]string{
+		"text/json":                         "application/json",
+		"image/jpg":                         "image/jpeg",
+		"application/x-json, image/*;q=0.5": "application/json",
+		"text/html":                         "",
+	}
+	for header, result := range headers {
+		if match := p.BestMatch(supported, header); match != result {
+			t.Errorf("BestMatch(%v, %v) == %s, not %s", supported, header, match, result)
+		}
+	}
+	if BestMatch(supported, "text/json") != "" {
+		t.Errorf("Aliases should only be normalized when the policy asks for it")
+	}
+	if q := p.Quality("image/jpg", "image/jpeg;q=0.3"); q != 0.3 {
+		t.Errorf("Quality of an alias == %f, not 0.3", q)
+	}
+}
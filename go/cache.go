@@ -0,0 +1,92 @@
+package mimeparse
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A bounded cache of parsed Accept headers, keyed by the raw header.
+// Browsers send a handful of distinct Accept headers, so a small
+// cache spares most requests the parsing. When full, the least
+// recently used header is evicted. A HeaderCache is safe for
+// concurrent use; the policy it was made with must not be changed
+// afterwards. Combined with a Matcher:
+//
+// m.MatchParsed(cache.ParseHeader(req.Header.Get("Accept")))
+type HeaderCache struct {
+	policy  *Policy
+	size    int
+	mutex   sync.Mutex
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	header string
+	parsed []Mime
+}
+
+// Returns a cache holding up to 'size' headers, at least one.
+func NewHeaderCache(size int) *HeaderCache {
+	return defaultPolicy.NewHeaderCache(size)
+}
+
+// Like NewHeaderCache() but parsing with the options of the policy.
+func (p *Policy) NewHeaderCache(size int) *HeaderCache {
+	if size < 1 {
+		size = 1
+	}
+	return &HeaderCache{policy: p, size: size, lru: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Like ParseHeader() but returns the ranges parsed earlier for the
+// same header if the cache still holds them.
+func (c *HeaderCache) ParseHeader(header string) []Mime {
+	c.mutex.Lock()
+	if e, ok := c.entries[header]; ok {
+		c.lru.MoveToFront(e)
+		parsed := e.Value.(*cacheEntry).parsed
+		c.mutex.Unlock()
+		return copyRanges(parsed)
+	}
+	c.mutex.Unlock()
+
+	parsed := c.policy.ParseHeader(header)
+	// The cached ranges share their parameters with every caller, so
+	// they are marked to be copied before any change, see setParam().
+	for i := range parsed {
+		parsed[i].shared = true
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.entries[header]; !ok {
+		c.entries[header] = c.lru.PushFront(&cacheEntry{header, parsed})
+		if c.lru.Len() > c.size {
+			c.evict()
+		}
+	}
+	return copyRanges(parsed)
+}
+
+// Removes the least recently used header. The caller must hold the
+// mutex.
+func (c *HeaderCache) evict() {
+	oldest := c.lru.Back()
+	c.lru.Remove(oldest)
+	c.entries[oldest.Value.(*cacheEntry).header] = nil, false
+}
+
+// Returns the number of headers in the cache.
+func (c *HeaderCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+// Copies a list of ranges, so that callers may reorder or change it
+// without affecting the cache.
+func copyRanges(ranges []Mime) []Mime {
+	c := make([]Mime, len(ranges))
+	copy(c, ranges)
+	return c
+}
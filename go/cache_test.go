@@ -0,0 +1,38 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestHeaderCache(t *testing.T) {
+	c := NewHeaderCache(2)
+	header := "text/html;level=1, application/json;q=0.5"
+	first := c.ParseHeader(header)
+	if len(first) != 2 || first[0].String() != "text/html;level=1;q=1" {
+		t.Errorf("ParseHeader(%s) == %v", header, first)
+	}
+	first[0].SetQ(0.1)
+	first[1] = Mime{}
+	second := c.ParseHeader(header)
+	if second[0].Q() != 1 || second[1].Subtype() != "json" {
+		t.Errorf("ParseHeader(%s) == %v after changing an earlier result", header, second)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() == %d, not 1", c.Len())
+	}
+	c.ParseHeader("text/plain")
+	c.ParseHeader(header)
+	c.ParseHeader("*/*")
+	if c.Len() != 2 {
+		t.Errorf("Len() == %d, not 2", c.Len())
+	}
+	if _, ok := c.entries["text/plain"]; ok {
+		t.Errorf("least recently used header was not evicted")
+	}
+	if _, ok := c.entries[header]; !ok {
+		t.Errorf("recently used header was evicted")
+	}
+	if NewHeaderCache(0).size != 1 {
+		t.Errorf("NewHeaderCache(0) does not hold one header")
+	}
+}
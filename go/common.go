@@ -0,0 +1,37 @@
+package mimeparse
+
+import (
+	"strings"
+)
+
+// The Accept headers sent by most clients, verbatim. Browsers send
+// the same few headers with every navigation, and API clients mostly
+// send a single type, so these account for much of real traffic.
+var commonHeaderList = []string{
+	"*/*",
+	"application/json",
+	// Chrome, Edge and Opera navigating to a page.
+	"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+	// Firefox navigating to a page, before and after version 128.
+	"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+	"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+}
+
+// The common headers parsed with the default options, see
+// parseHeader(). Each is also found in the form requestAccept() gives
+// it when joining the lines of a request header, with ', ' between
+// the ranges.
+var commonHeaders = make(map[string][]Mime)
+
+func init() {
+	for _, header := range commonHeaderList {
+		parsed, _ := defaultPolicy.parseHeader(header)
+		// Every caller gets the same ranges, so they are copied
+		// before any change, see setParam().
+		for i := range parsed {
+			parsed[i].shared = true
+		}
+		commonHeaders[header] = parsed
+		commonHeaders[strings.Join(ParseList(header), ", ")] = parsed
+	}
+}
@@ -0,0 +1,92 @@
+package mimeparse
+
+import (
+	"http"
+	"testing"
+)
+
+func formatRanges(ranges []Mime) string {
+	s := ""
+	for _, r := range ranges {
+		s += r.String() + ", "
+	}
+	return s
+}
+
+func TestCommonHeaders(t *testing.T) {
+	for _, header := range commonHeaderList {
+		result, expected := formatRanges(ParseHeader(header)), formatRanges((&Policy{}).ParseHeader(header))
+		if result != expected {
+			t.Errorf("ParseHeader(%s) == %s, not %s", header, result, expected)
+		}
+	}
+	chrome := commonHeaderList[2]
+	parsed := ParseHeader(chrome)
+	parsed[0].SetQ(0.1)
+	parsed[1] = Mime{}
+	Recycle(parsed)
+	parsed = ParseHeader(chrome)
+	if parsed[0].Q() != 1 || parsed[1].Subtype() != "xhtml+xml" {
+		t.Errorf("ParseHeader(%s) == %s after changing an earlier result", chrome, formatRanges(parsed))
+	}
+	if best := BestMatch([]string{"application/json", "text/html"}, chrome); best != "text/html" {
+		t.Errorf("BestMatch([application/json, text/html], %s) == %s, not text/html", chrome, best)
+	}
+}
+
+func TestCommonRequestHeaders(t *testing.T) {
+	chrome := commonHeaderList[2]
+	cond := []struct {
+		accept []string
+		common bool
+	}{
+		{[]string{chrome}, true},
+		{[]string{"text/html,application/xhtml+xml", "application/xml;q=0.9,*/*;q=0.8"}, true},
+		{[]string{"text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8"}, true},
+		{[]string{"text/html,application/xhtml+xml,application/xml;q=0.8"}, false},
+	}
+	for _, c := range cond {
+		h := http.Header{"Accept": c.accept}
+		// Ranges parsed in advance are shared, while parsing
+		// 'application/xml;q=0.9' gives a range of its own.
+		if parsed := ParseAcceptHeader(h); parsed[2].shared != c.common {
+			t.Errorf("ParseAcceptHeader(%v) used the parsed common header: %v", c.accept, parsed[2].shared)
+		}
+	}
+	req := &http.Request{Header: http.Header{"Accept": {chrome}}}
+	if accept := requestAccept(req.Header); accept != chrome {
+		t.Errorf("requestAccept(%s) == %s", chrome, accept)
+	}
+	if mime, err := NegotiateRequest([]string{"application/json", "text/html"}, req); mime != "text/html" || err != nil {
+		t.Errorf("NegotiateRequest(%s) == %s, %v", chrome, mime, err)
+	}
+}
+
+// A mix of headers as seen by a site serving both pages and an API,
+// most of them sent by browsers.
+var trafficHeaders = []string{
+	commonHeaderList[2],
+	commonHeaderList[2],
+	commonHeaderList[2],
+	commonHeaderList[4],
+	commonHeaderList[3],
+	"application/json",
+	"*/*",
+	"application/json, text/plain, */*",
+}
+
+func benchmarkTraffic(b *testing.B, p *Policy) {
+	supported := []string{"application/json", "text/html"}
+	for i := 0; i < b.N; i++ {
+		p.BestMatch(supported, trafficHeaders[i%len(trafficHeaders)])
+	}
+}
+
+func BenchmarkTraffic(b *testing.B) {
+	benchmarkTraffic(b, defaultPolicy)
+}
+
+// The same mix without the common headers parsed in advance.
+func BenchmarkTrafficParsed(b *testing.B) {
+	benchmarkTraffic(b, &Policy{})
+}
@@ -0,0 +1,104 @@
+package mimeparse
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A Content-Disposition header, see RFC 6266.
+type ContentDisposition struct {
+	// The disposition type in lower case, such as 'inline' or
+	// 'attachment'.
+	Type string
+
+	// The decoded filename, "" if there is none. Recipients should
+	// keep only its last path segment before using it.
+	Filename string
+
+	// The parameters as they appear in the header, with names in
+	// lower case and values unquoted but not otherwise decoded. When
+	// formatting, the filename parameters are ignored in favour of
+	// Filename.
+	Params map[string]string
+}
+
+// Parses a Content-Disposition header. The filename is taken from
+// the extended 'filename*' parameter when present and decodable, as
+// RFC 6266 prescribes, and from 'filename' otherwise, see
+// Mime.DecodedParam(). For example the filename of
+//
+//	attachment; filename="EURO rates"; filename*=utf-8''%e2%82%ac%20rates
+//
+// is '€ rates'.
+func ParseContentDisposition(value string) (ContentDisposition, os.Error) {
+	if err := checkControl(value); err != nil {
+		return ContentDisposition{}, err
+	}
+	dispositionType, parts := ht(splitQuoted(value, ';'))
+	dispositionType = strings.ToLower(strings.TrimSpace(dispositionType))
+	if !isToken(dispositionType) {
+		return ContentDisposition{}, os.NewError("Not a valid disposition type")
+	}
+	m := Mime{}
+	for _, s := range parts {
+		subparts := strings.Split(s, "=", 2)
+		key := strings.ToLower(strings.TrimSpace(subparts[0]))
+		if key == "" {
+			continue
+		}
+		if len(subparts) != 2 {
+			return ContentDisposition{}, os.NewError("Parameter without a value: " + key)
+		}
+		m.setParam(key, unquote(strings.TrimSpace(subparts[1])))
+	}
+	filename, _ := m.DecodedParam("filename")
+	return ContentDisposition{dispositionType, filename, m.params.toMap()}, nil
+}
+
+// Formats a Content-Disposition header. A filename that is printable
+// ASCII is written as a 'filename' parameter, quoted if needed. Any
+// other filename is written as an extended 'filename*' in UTF-8,
+// preceded by a 'filename' with the other characters replaced by '_'
+// for recipients that predate RFC 6266. The remaining parameters
+// follow sorted by name.
+//
+// FormatContentDisposition(ContentDisposition{Type: 'attachment', Filename: 'naïve.txt'})
+//
+//	'attachment; filename=na__ve.txt; filename*=UTF-8''na%C3%AFve.txt'
+func FormatContentDisposition(d ContentDisposition) string {
+	b := bytes.NewBufferString(strings.ToLower(d.Type))
+	if d.Filename != "" {
+		fallback, ascii := asciiFallback(d.Filename)
+		b.WriteString("; filename=" + quoteIfNeeded(fallback))
+		if !ascii {
+			b.WriteString("; filename*=" + encodeExtValue(d.Filename))
+		}
+	}
+	keys := []string{}
+	for k := range d.Params {
+		if k != "filename" && !strings.HasPrefix(k, "filename*") {
+			keys = append(keys, k)
+		}
+	}
+	sort.SortStrings(keys)
+	for _, k := range keys {
+		b.WriteString("; " + k + "=" + quoteIfNeeded(d.Params[k]))
+	}
+	return b.String()
+}
+
+// Replaces every byte of 's' that is not printable ASCII with '_',
+// reporting whether there were none.
+func asciiFallback(s string) (string, bool) {
+	b := []byte(s)
+	ascii := true
+	for i, c := range b {
+		if c < ' ' || c > '~' {
+			b[i] = '_'
+			ascii = false
+		}
+	}
+	return string(b), ascii
+}
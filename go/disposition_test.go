@@ -0,0 +1,53 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestParseContentDisposition(t *testing.T) {
+	cond := []struct {
+		value, dispositionType, filename string
+	}{
+		{"inline", "inline", ""},
+		{"Attachment; filename=foo.html", "attachment", "foo.html"},
+		{"attachment; filename=\"a;b \\\"c\\\".txt\"", "attachment", "a;b \"c\".txt"},
+		{"attachment; filename*=UTF-8''na%C3%AFve.txt", "attachment", "naïve.txt"},
+		{"attachment; filename=\"EURO rates\"; filename*=utf-8''%e2%82%ac%20rates", "attachment", "€ rates"},
+		{"attachment; filename*=utf-8''%e2%82%ac%20rates; filename=\"EURO rates\"", "attachment", "€ rates"},
+		{"attachment; filename=fallback.txt; filename*=bogus''x", "attachment", "fallback.txt"},
+		{"form-data; name=field; filename*0*=UTF-8''na%C3%AF; filename*1=ve.txt", "form-data", "naïve.txt"},
+	}
+	for _, c := range cond {
+		d, err := ParseContentDisposition(c.value)
+		if err != nil || d.Type != c.dispositionType || d.Filename != c.filename {
+			t.Errorf("ParseContentDisposition(%s) == %v, %v, not %s, %s", c.value, d, err, c.dispositionType, c.filename)
+		}
+	}
+	for _, value := range []string{"", "; filename=foo", "attachment; filename"} {
+		if _, err := ParseContentDisposition(value); err == nil {
+			t.Errorf("ParseContentDisposition(%s) did not fail", value)
+		}
+	}
+}
+
+func TestFormatContentDisposition(t *testing.T) {
+	cond := []struct {
+		d        ContentDisposition
+		expected string
+	}{
+		{ContentDisposition{Type: "inline"}, "inline"},
+		{ContentDisposition{Type: "attachment", Filename: "foo.html"}, "attachment; filename=foo.html"},
+		{ContentDisposition{Type: "attachment", Filename: "my file.txt"}, "attachment; filename=\"my file.txt\""},
+		{ContentDisposition{Type: "attachment", Filename: "naïve.txt"}, "attachment; filename=na__ve.txt; filename*=UTF-8''na%C3%AFve.txt"},
+		{ContentDisposition{"form-data", "a.txt", map[string]string{"name": "upload", "filename": "ignored", "filename*": "x"}}, "form-data; filename=a.txt; name=upload"},
+	}
+	for _, c := range cond {
+		if result := FormatContentDisposition(c.d); result != c.expected {
+			t.Errorf("FormatContentDisposition(%v) == %s, not %s", c.d, result, c.expected)
+		}
+		d, err := ParseContentDisposition(c.expected)
+		if err != nil || d.Type != c.d.Type || d.Filename != c.d.Filename {
+			t.Errorf("ParseContentDisposition(%s) == %v, %v, not %v", c.expected, d, err, c.d)
+		}
+	}
+}
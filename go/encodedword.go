@@ -0,0 +1,104 @@
+package mimeparse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"strings"
+)
+
+// Decodes the RFC 2047 encoded-words in 's', such as
+// '=?UTF-8?B?bmHDr3ZlLnR4dA==?=', which mail software puts in
+// parameters like 'name' although RFC 2047 does not allow them there.
+// Both the 'B' and 'Q' encodings are decoded, from the charsets
+// UTF-8, ISO-8859-1 and US-ASCII. Whitespace between two adjacent
+// encoded-words is removed, and words that cannot be decoded are
+// left as they are. For example:
+//
+// DecodeEncodedWords('=?UTF-8?Q?na=C3=AFve?= =?UTF-8?Q?_file.txt?=')
+// 'naïve file.txt'
+func DecodeEncodedWords(s string) string {
+	b := new(bytes.Buffer)
+	afterWord := false
+	for i := 0; i < len(s); {
+		if decoded, n, ok := decodeWord(s[i:]); ok {
+			b.WriteString(decoded)
+			i += n
+			afterWord = true
+			continue
+		}
+		if afterWord && (s[i] == ' ' || s[i] == '\t') {
+			j := i
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+				j++
+			}
+			if _, _, ok := decodeWord(s[j:]); ok {
+				i = j
+				continue
+			}
+		}
+		afterWord = false
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// Decodes the encoded-word at the start of 's', returning it along
+// with its length in 's'.
+func decodeWord(s string) (decoded string, n int, ok bool) {
+	if !strings.HasPrefix(s, "=?") {
+		return "", 0, false
+	}
+	parts := strings.Split(s[2:], "?", 4)
+	if len(parts) != 4 || !strings.HasPrefix(parts[3], "=") || strings.IndexAny(parts[2], " \t") >= 0 {
+		return "", 0, false
+	}
+	// RFC 2231 allows a language after the charset, as in 'UTF-8*en'.
+	charset := parts[0]
+	if i := strings.Index(charset, "*"); i >= 0 {
+		charset = charset[:i]
+	}
+	var raw string
+	var err os.Error
+	switch strings.ToLower(parts[1]) {
+	case "b":
+		var data []byte
+		data, err = base64.StdEncoding.DecodeString(parts[2])
+		raw = string(data)
+	case "q":
+		raw, err = decodeQ(parts[2])
+	default:
+		return "", 0, false
+	}
+	if err == nil {
+		decoded, err = decodeCharset(charset, raw)
+	}
+	return decoded, len(parts[0]) + len(parts[1]) + len(parts[2]) + 6, err == nil
+}
+
+// Decodes the 'Q' encoding of RFC 2047, where '_' is a space and
+// =XX the byte XX.
+func decodeQ(s string) (string, os.Error) {
+	b := new(bytes.Buffer)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '_':
+			b.WriteByte(' ')
+		case '=':
+			if i+2 >= len(s) {
+				return "", os.NewError("Truncated Q-encoding")
+			}
+			hi, ok1 := unhex(s[i+1])
+			lo, ok2 := unhex(s[i+2])
+			if !ok1 || !ok2 {
+				return "", os.NewError("Invalid Q-encoding")
+			}
+			b.WriteByte(hi<<4 | lo)
+			i += 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,34 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestDecodeEncodedWords(t *testing.T) {
+	cond := map[string]string{
+		"plain.txt":                                        "plain.txt",
+		"=?UTF-8?B?bmHDr3ZlLnR4dA==?=":                     "naïve.txt",
+		"=?utf-8?q?na=C3=AFve_file.txt?=":                  "naïve file.txt",
+		"=?UTF-8?Q?na=C3=AFve?= =?UTF-8?Q?_file.txt?=":     "naïve file.txt",
+		"=?ISO-8859-1?Q?caf=E9?= au lait":                  "café au lait",
+		"a =?UTF-8*en?Q?b?= c":                             "a b c",
+		"=?KOI8-R?Q?=C1?=":                                 "=?KOI8-R?Q?=C1?=",
+		"=?UTF-8?X?abc?=":                                  "=?UTF-8?X?abc?=",
+		"=?UTF-8?Q?bad=Z?=":                                "=?UTF-8?Q?bad=Z?=",
+		"=?UTF-8?B?not base64?=":                           "=?UTF-8?B?not base64?=",
+		"=?UTF-8?Q?a?=\t\t=?UTF-8?Q?b?=  =?UTF-8?Q?broken": "ab  =?UTF-8?Q?broken",
+	}
+	for in, out := range cond {
+		if result := DecodeEncodedWords(in); result != out {
+			t.Errorf("DecodeEncodedWords(%s) == %s, not %s", in, result, out)
+		}
+	}
+	header := "application/pdf; name=\"=?UTF-8?B?bmHDr3ZlLnBkZg==?=\""
+	if m, _ := ParseMimeType(header); m.Params()["name"] != "=?UTF-8?B?bmHDr3ZlLnBkZg==?=" {
+		t.Errorf("Encoded-words should only be decoded when the policy asks for it")
+	}
+	p := &Policy{EncodedWords: true}
+	if m, _ := p.ParseMimeType(header); m.Params()["name"] != "naïve.pdf" {
+		t.Errorf("ParseMimeType(%s) name == %s", header, m.Params()["name"])
+	}
+}
@@ -0,0 +1,200 @@
+package mimeparse
+
+import (
+	"strings"
+)
+
+// Content-codings registered for HTTP.
+const (
+	Identity = "identity"
+	Gzip     = "gzip"
+	Deflate  = "deflate"
+	Compress = "compress"
+	Brotli   = "br"
+	Zstd     = "zstd"
+)
+
+// The quality given to 'identity' when the header neither names it
+// nor has a '*'. It is acceptable, but any coding the client listed
+// is preferred.
+const implicitIdentityQuality = 0.001
+
+// Names that RFC 7230 section 4.2 says a recipient should treat as
+// equivalent to a registered coding.
+var codingAliases = map[string]string{
+	"x-gzip":     Gzip,
+	"x-compress": Compress,
+}
+
+// Lower-cases a content-coding and resolves its aliases.
+func normalizeCoding(coding string) string {
+	coding = strings.ToLower(strings.TrimSpace(coding))
+	if name, ok := codingAliases[coding]; ok {
+		return name
+	}
+	return coding
+}
+
+// Returns the quality of a content-coding under an Accept-Encoding
+// header that has already been parsed, as described in RFC 7231
+// section 5.3.4. A coding the header names gets its own 'q', any
+// other coding the 'q' of '*'. Without either, 'identity' remains
+// acceptable at a low quality while every other coding gets 0.
+func encodingQuality(coding string, accepted []weighted) float {
+	coding = normalizeCoding(coding)
+	star := -1.0
+	for _, w := range accepted {
+		switch normalizeCoding(w.value) {
+		case coding:
+			return w.q
+		case "*":
+			if star < 0 {
+				star = w.q
+			}
+		}
+	}
+	switch {
+	case star >= 0:
+		return star
+	case coding == Identity:
+		return implicitIdentityQuality
+	}
+	return 0
+}
+
+// Returns the quality of a content-coding when compared against an
+// Accept-Encoding header. For example:
+//
+// EncodingQuality('gzip', 'gzip;q=0.8, br')
+// 0.8
+// EncodingQuality('identity', 'gzip')
+// 0.001
+func EncodingQuality(coding string, header string) float {
+	return encodingQuality(coding, parseWeighted(header))
+}
+
+// Takes a list of supported content-codings, such as 'br', 'gzip' and
+// 'identity', and finds the one most acceptable to an Accept-Encoding
+// header. Ties go to the coding listed first in 'supported'. An empty
+// header accepts only 'identity', which is also acceptable whenever
+// the header does not exclude it with 'identity;q=0' or '*;q=0'.
+// Returns "" if none of the supported codings is acceptable, in which
+// case a server may respond with 406 Not Acceptable or disregard the
+// header and send the response without a coding.
+//
+// BestEncoding(['br', 'gzip', 'identity'], 'gzip, deflate')
+// 'gzip'
+// BestEncoding(['br', 'identity'], 'gzip')
+// 'identity'
+func BestEncoding(supported []string, header string) string {
+	return bestWeighted(supported, parseWeighted(header), encodingQuality)
+}
+
+// A transfer-coding listed in a TE header, with its parameters
+// other than 'q'.
+type TransferCoding struct {
+	Name    string
+	Params  map[string]string
+	Quality float
+}
+
+// The content of a TE request header, see RFC 7230 section 4.3.
+type TE struct {
+	// Whether the client listed 'trailers', that is it accepts
+	// trailer fields in a chunked response.
+	Trailers bool
+	// The transfer-codings the client accepts, in header order.
+	Codings []TransferCoding
+}
+
+// Parses a TE header, such as 'trailers, deflate;q=0.5'. Coding
+// names are lower-cased, 'x-gzip' and 'x-compress' are read as
+// 'gzip' and 'compress'.
+func ParseTE(header string) TE {
+	te := TE{}
+	for _, w := range parseWeighted(header) {
+		if w.value == "trailers" {
+			te.Trailers = true
+			continue
+		}
+		params := make(map[string]string)
+		for k, v := range w.params {
+			if k != "q" {
+				params[k] = v
+			}
+		}
+		te.Codings = append(te.Codings, TransferCoding{normalizeCoding(w.value), params, w.q})
+	}
+	return te
+}
+
+// Returns the quality of a transfer-coding under the TE header.
+// 'chunked' is always acceptable to an HTTP/1.1 client, so it gets
+// a quality of 1 unless the header says otherwise. Any other coding
+// must be listed to be acceptable.
+func (te TE) Quality(coding string) float {
+	coding = normalizeCoding(coding)
+	for _, c := range te.Codings {
+		if c.Name == coding {
+			return c.Quality
+		}
+	}
+	if coding == "chunked" {
+		return 1
+	}
+	return 0
+}
+
+// Takes a list of supported transfer-codings and finds the one most
+// acceptable to a TE header, preferring the one listed first among
+// equals. Returns "" if none is acceptable.
+//
+// BestTransferCoding(['gzip', 'chunked'], 'trailers, gzip;q=0.5')
+// 'chunked'
+func BestTransferCoding(supported []string, header string) string {
+	te := ParseTE(header)
+	best, bestQ := "", 0.0
+	for _, coding := range supported {
+		if q := te.Quality(coding); q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// Types worth compressing besides the textual ones, mostly binary
+// formats stored without compression. Formats that are already
+// compressed, such as 'image/jpeg', 'video/mp4' or 'application/zip',
+// are left out, as are most binary formats.
+var compressibleTypes = map[string]bool{
+	"image/bmp":                     true,
+	"image/x-ms-bmp":                true,
+	"image/vnd.microsoft.icon":      true,
+	"image/x-icon":                  true,
+	"image/tiff":                    true,
+	"audio/wav":                     true,
+	"audio/x-wav":                   true,
+	"font/ttf":                      true,
+	"font/otf":                      true,
+	"application/vnd.ms-fontobject": true,
+	"application/wasm":              true,
+	"application/x-tar":             true,
+	"application/cbor":              true,
+	"application/x-protobuf":        true,
+	"application/grpc":              true,
+}
+
+// Reports whether a response of the type is worth compressing with a
+// content-coding such as 'gzip', for middleware deciding whether to
+// compress a negotiated response: textual types, see Mime.IsText(),
+// and a few uncompressed binary formats are, while formats that are
+// compressed already, such as images, audio, video and archives, are
+// not. Types with the '+zip' or '+gzip' suffix never are, and unknown
+// binary types are taken to be compressed.
+func IsCompressible(mime Mime) bool {
+	switch mime.Suffix() {
+	case "zip", "gzip":
+		return false
+	}
+	return mime.IsText() || compressibleTypes[mime.mtype+"/"+mime.subtype]
+}
@@ -0,0 +1,108 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestEncodingQuality(t *testing.T) {
+	cond := []struct {
+		coding, header string
+		q              float
+	}{
+		{"gzip", "gzip;q=0.8, br", 0.8},
+		{"GZIP", "x-gzip;q=0.5", 0.5},
+		{"br", "gzip", 0},
+		{"br", "gzip, *;q=0.3", 0.3},
+		{"identity", "gzip", 0.001},
+		{"identity", "", 0.001},
+		{"identity", "identity;q=0", 0},
+		{"identity", "*;q=0", 0},
+		{"identity", "*;q=0, identity;q=0.5", 0.5},
+		{"gzip", "gzip;q=0, *", 0},
+	}
+	for _, c := range cond {
+		if got := EncodingQuality(c.coding, c.header); got != c.q {
+			t.Errorf("EncodingQuality(%s, %s) == %v, not %v", c.coding, c.header, got, c.q)
+		}
+	}
+}
+
+func TestBestEncoding(t *testing.T) {
+	supported := []string{Zstd, Brotli, Gzip, Identity}
+	cond := map[string]string{
+		"gzip, deflate, br":          "br",
+		"gzip;q=1, br;q=0.5":         "gzip",
+		"deflate":                    "identity",
+		"":                           "identity",
+		"*":                          "zstd",
+		"*;q=0.5, zstd;q=0":          "br",
+		"deflate, identity;q=0":      "",
+		"deflate, *;q=0":             "",
+		"x-gzip;q=0.2, identity;q=0": "gzip",
+	}
+	for header, want := range cond {
+		if got := BestEncoding(supported, header); got != want {
+			t.Errorf("BestEncoding(%s) == %s, not %s", header, got, want)
+		}
+	}
+}
+
+func TestParseTE(t *testing.T) {
+	te := ParseTE(`trailers, Deflate;q=0.5, x-gzip;level="9";q=0.2`)
+	if !te.Trailers || len(te.Codings) != 2 {
+		t.Fatalf("ParseTE() == %v", te)
+	}
+	c := te.Codings[1]
+	if c.Name != "gzip" || c.Quality != 0.2 || len(c.Params) != 1 || c.Params["level"] != "9" {
+		t.Errorf("ParseTE() coding == %v", c)
+	}
+	if te.Quality("deflate") != 0.5 || te.Quality("chunked") != 1 || te.Quality("br") != 0 {
+		t.Errorf("TE.Quality() of %v wrong", te)
+	}
+	if te := ParseTE("gzip"); te.Trailers {
+		t.Errorf("ParseTE(gzip).Trailers == true")
+	}
+}
+
+func TestBestTransferCoding(t *testing.T) {
+	supported := []string{"gzip", "chunked"}
+	cond := map[string]string{
+		"trailers, gzip;q=0.5":     "chunked",
+		"gzip":                     "gzip",
+		"":                         "chunked",
+		"chunked;q=0, gzip;q=0.1":  "gzip",
+		"chunked;q=0, deflate;q=1": "",
+	}
+	for header, want := range cond {
+		if got := BestTransferCoding(supported, header); got != want {
+			t.Errorf("BestTransferCoding(%s) == %s, not %s", header, got, want)
+		}
+	}
+}
+
+func TestIsCompressible(t *testing.T) {
+	cond := map[string]bool{
+		"text/html; charset=utf-8": true,
+		"application/json":         true,
+		"application/vnd.api+json": true,
+		"image/svg+xml":            true,
+		"image/bmp":                true,
+		"font/ttf":                 true,
+		"image/jpeg":               false,
+		"image/png":                false,
+		"video/mp4":                false,
+		"audio/mpeg":               false,
+		"application/zip":          false,
+		"application/gzip":         false,
+		"font/woff2":               false,
+		"application/epub+zip":     false,
+		"application/octet-stream": false,
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": false,
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := IsCompressible(m); got != want {
+			t.Errorf("IsCompressible(%s) == %v, not %v", mime, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package mimeparse
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// How one media-range of a header relates to a supported type.
+type RangeTrace struct {
+	Range Mime
+	// The fitness of Range for the type, -1 if it does not match,
+	// see FitnessAndQuality().
+	Fitness     int
+	Specificity Specificity
+	// Whether this is the best fitting range, the one that gives
+	// the type its quality.
+	Chosen bool
+}
+
+// How a supported type fared against a header.
+type Candidate struct {
+	Type   string
+	Ranges []RangeTrace
+	// The outcome for the type, valid if Matched is set.
+	Match   Match
+	Matched bool
+	// Whether the type was matched, but with a 'q' of 0.
+	Excluded bool
+}
+
+// A record of a negotiation, for logging and debugging.
+type Explanation struct {
+	Header     string
+	Candidates []Candidate
+	// The chosen type, valid if Found is set.
+	Winner Match
+	Found  bool
+	// Why the winner won, or why nothing did.
+	Reason string
+}
+
+// Negotiates like BestMatchRange() but records every step: for each
+// supported type how each media-range of the header fits it, which
+// range decided its quality, whether it was excluded, and why the
+// winner was chosen over the runner-up.
+func Explain(supported []string, header string) Explanation {
+	return defaultPolicy.Explain(supported, header)
+}
+
+// Like Explain() but applying the options of the policy.
+func (p *Policy) Explain(supported []string, header string) Explanation {
+	parsedHeader := p.ParseHeader(header)
+	e := Explanation{Header: header}
+	matches := []Match{}
+	targets := make([]Mime, len(supported))
+	for i, mime := range supported {
+		c := Candidate{Type: mime}
+		target := p.parseTarget(mime)
+		targets[i] = target
+		c.Match, c.Matched = p.matchTarget(i, mime, target, parsedHeader)
+		c.Excluded = c.Matched && c.Match.Quality <= 0
+		for j, r := range parsedHeader {
+			if p.NormalizeAliases {
+				r = Normalize(r)
+			}
+			t := RangeTrace{parsedHeader[j], p.rangeFitness(target, r), NoMatch, c.Matched && c.Match.rangeIndex == j}
+			if t.Fitness >= 0 {
+				t.Specificity, _ = p.specificity(target, r)
+			}
+			c.Ranges = append(c.Ranges, t)
+		}
+		if c.Matched && !c.Excluded {
+			matches = append(matches, c.Match)
+		}
+		e.Candidates = append(e.Candidates, c)
+	}
+	// Only a negotiation made for a caller is told to the observer.
+	e.Winner, e.Found = p.bestTargetMatch(supported, targets, parsedHeader)
+	e.Reason = p.reason(e.Winner, e.Found, matches)
+	return e
+}
+
+// Explains why 'winner' beat the other acceptable matches.
+func (p *Policy) reason(winner Match, found bool, matches []Match) string {
+	if !found {
+		return "no supported type is acceptable"
+	}
+	runnerUp := noMatch
+	for _, m := range matches {
+		if m.index != winner.index && (m.Quality > runnerUp.Quality || (m.Quality == runnerUp.Quality && p.winsTie(m, runnerUp))) {
+			runnerUp = m
+		}
+	}
+	if runnerUp.index < 0 {
+		return "the only acceptable type"
+	}
+	if winner.Quality > runnerUp.Quality {
+		return fmt.Sprintf("higher quality %v than %s with %v", winner.Quality, runnerUp.Type, runnerUp.Quality)
+	}
+	if _, rule := p.breakTie(winner, runnerUp); rule != "" {
+		return fmt.Sprintf("same quality %v as %s, preferred by %s", winner.Quality, runnerUp.Type, rule)
+	}
+	return fmt.Sprintf("same quality %v as %s, listed earlier in the supported types", winner.Quality, runnerUp.Type)
+}
+
+// Formats the explanation as lines of text.
+func (e Explanation) String() string {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "Accept: %s\n", e.Header)
+	for _, c := range e.Candidates {
+		switch {
+		case c.Excluded:
+			fmt.Fprintf(b, "%s: excluded by %s\n", c.Type, c.Match.Range)
+		case c.Matched:
+			fmt.Fprintf(b, "%s: q=%v from %s (%v, fitness %d)\n", c.Type, c.Match.Quality, c.Match.Range, c.Match.Specificity, c.Match.Fitness)
+		default:
+			fmt.Fprintf(b, "%s: no matching range\n", c.Type)
+		}
+	}
+	if e.Found {
+		fmt.Fprintf(b, "chose %s: %s\n", e.Winner.Type, e.Reason)
+	} else {
+		fmt.Fprintf(b, "chose nothing: %s\n", e.Reason)
+	}
+	return b.String()
+}
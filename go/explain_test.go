@@ -0,0 +1,53 @@
+package mimeparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	supported := []string{"application/xml", "application/json", "text/html"}
+	e := Explain(supported, "application/*;q=0.5, application/json;q=0.5, text/html;q=0")
+	if !e.Found || e.Winner.Type != "application/xml" || len(e.Candidates) != 3 {
+		t.Fatalf("Explain() == %v", e)
+	}
+	if e.Reason != "same quality 0.5 as application/json, listed earlier in the supported types" {
+		t.Errorf("Explain().Reason == %s", e.Reason)
+	}
+	json := e.Candidates[1]
+	if !json.Matched || json.Excluded || len(json.Ranges) != 3 || json.Ranges[0].Chosen || !json.Ranges[1].Chosen ||
+		json.Ranges[0].Specificity != TypeMatch || json.Ranges[1].Specificity != ExactMatch || json.Ranges[2].Fitness != -1 {
+		t.Errorf("Explain() candidate == %v", json)
+	}
+	if html := e.Candidates[2]; !html.Excluded {
+		t.Errorf("Explain() text/html not excluded: %v", html)
+	}
+	if s := e.String(); !strings.Contains(s, "text/html: excluded by text/html;q=0\n") || !strings.HasSuffix(s, "chose application/xml: "+e.Reason+"\n") {
+		t.Errorf("Explain().String() == %s", s)
+	}
+
+	cond := []struct {
+		policy *Policy
+		header string
+		reason string
+	}{
+		{&Policy{}, "application/json, */*;q=0.1", "higher quality 1 than application/xml with 0.1"},
+		{&Policy{ClientOrder: true}, "application/json, application/xml", "same quality 1 as application/xml, preferred by ClientOrder"},
+		{&Policy{}, "text/*", "the only acceptable type"},
+		{&Policy{}, "image/png", "no supported type is acceptable"},
+	}
+	for _, c := range cond {
+		if e := c.policy.Explain(supported, c.header); e.Reason != c.reason {
+			t.Errorf("Explain(%s).Reason == %s, not %s", c.header, e.Reason, c.reason)
+		}
+	}
+}
+
+func TestExplainIsNotObserved(t *testing.T) {
+	p := &Policy{Observer: ObserverFunc(func(m Match, ok bool) {
+		t.Errorf("Explain() told the observer about %v", m)
+	})}
+	if e := p.Explain([]string{"text/html"}, "text/*"); !e.Found || e.Winner.Type != "text/html" {
+		t.Errorf("Explain([text/html], text/*) == %v", e)
+	}
+}
@@ -0,0 +1,112 @@
+package mimeparse
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	extensionMutex sync.RWMutex
+	extensionTypes = make(map[string]string)   // ".html" -> "text/html"
+	typeExtensions = make(map[string][]string) // "text/html" -> [".html", ".htm"]
+)
+
+func init() {
+	for _, line := range extensionTable {
+		fields := strings.Fields(line)
+		for _, ext := range fields[1:] {
+			addExtension("."+ext, fields[0], false)
+		}
+	}
+}
+
+// Lower-cases an extension and makes sure it starts with a dot.
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// Records a mapping between an extension and a type. Unless 'override'
+// is set an existing mapping for the extension is kept. The caller
+// must hold extensionMutex for writing, or be running init.
+func addExtension(ext, typ string, override bool) {
+	if _, ok := extensionTypes[ext]; override || !ok {
+		extensionTypes[ext] = typ
+	}
+	for _, e := range typeExtensions[typ] {
+		if e == ext {
+			return
+		}
+	}
+	typeExtensions[typ] = append(typeExtensions[typ], ext)
+}
+
+// Returns the media type associated with the file extension 'ext',
+// which may be given with or without its leading dot. Returns ""
+// if the extension is unknown. For example:
+//
+// TypeByExtension(".svg")
+// 'image/svg+xml'
+func TypeByExtension(ext string) string {
+	extensionMutex.RLock()
+	defer extensionMutex.RUnlock()
+	return extensionTypes[normalizeExtension(ext)]
+}
+
+// Returns the file extensions known for a media type, each with
+// a leading dot and the preferred extension first. Parameters
+// of 'mimetype' are ignored.
+func ExtensionsByType(mimetype string) ([]string, os.Error) {
+	parsed, err := ParseMimeType(mimetype)
+	if err != nil {
+		return nil, err
+	}
+	extensionMutex.RLock()
+	defer extensionMutex.RUnlock()
+	exts := typeExtensions[parsed.mtype+"/"+parsed.subtype]
+	result := make([]string, len(exts))
+	copy(result, exts)
+	return result, nil
+}
+
+// Registers a custom mapping from the file extension 'ext' to the
+// media type 'mimetype', replacing any existing mapping for 'ext'.
+// The type must be fully specified; parameters are dropped.
+func AddExtensionType(ext, mimetype string) os.Error {
+	parsed, err := ParseMediaType(mimetype)
+	if err != nil {
+		return err
+	}
+	if ext = normalizeExtension(strings.TrimSpace(ext)); ext == "." {
+		return os.NewError("Empty file extension")
+	}
+	extensionMutex.Lock()
+	defer extensionMutex.Unlock()
+	addExtension(ext, parsed.Type()+"/"+parsed.Subtype(), true)
+	return nil
+}
+
+// Returns the preferred file extension of a media type, with its
+// leading dot, for naming a file that holds a negotiated
+// representation. A deprecated name is looked up under its current
+// name, see Normalize(). Returns false if no extension is known.
+//
+// PreferredExtension(ParseMimeType("text/html;charset=utf-8"))
+// '.html', true
+func PreferredExtension(mime Mime) (string, bool) {
+	extensionMutex.RLock()
+	defer extensionMutex.RUnlock()
+	exts := typeExtensions[mime.mtype+"/"+mime.subtype]
+	if len(exts) == 0 {
+		n := Normalize(mime)
+		exts = typeExtensions[n.mtype+"/"+n.subtype]
+	}
+	if len(exts) == 0 {
+		return "", false
+	}
+	return exts[0], true
+}
@@ -0,0 +1,73 @@
+package mimeparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeByExtension(t *testing.T) {
+	cond := map[string]string{
+		".html":   "text/html",
+		"html":    "text/html",
+		".HTM":    "text/html",
+		".json":   "application/json",
+		".svg":    "image/svg+xml",
+		".jpg":    "image/jpeg",
+		".nosuch": "",
+		"":        "",
+	}
+	for ext, typ := range cond {
+		if got := TypeByExtension(ext); got != typ {
+			t.Errorf("TypeByExtension(%s) == %s, not %s", ext, got, typ)
+		}
+	}
+}
+
+func TestExtensionsByType(t *testing.T) {
+	exts, err := ExtensionsByType("Image/JPEG; q=0.5")
+	if err != nil || len(exts) == 0 || exts[0] != ".jpeg" {
+		t.Errorf("ExtensionsByType(image/jpeg) == %v, %v", exts, err)
+	}
+	exts, err = ExtensionsByType("application/no-such-type")
+	if err != nil || len(exts) != 0 {
+		t.Errorf("ExtensionsByType of an unknown type == %v, %v", exts, err)
+	}
+	if _, err := ExtensionsByType("bogus"); err == nil {
+		t.Errorf("ExtensionsByType should fail on an invalid type")
+	}
+}
+
+func TestPreferredExtension(t *testing.T) {
+	cond := map[string]string{
+		"text/html; charset=utf-8": ".html",
+		"Image/JPEG":               ".jpeg",
+		"image/jpg":                ".jpeg",
+		"application/json":         ".json",
+		"application/no-such-type": "",
+		"image/*":                  "",
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if got, ok := PreferredExtension(m); got != want || ok != (want != "") {
+			t.Errorf("PreferredExtension(%s) == %s, %v, not %s", mime, got, ok, want)
+		}
+	}
+}
+
+func TestAddExtensionType(t *testing.T) {
+	if err := AddExtensionType("mimeparse-test", "application/vnd.mimeparse.test+json; charset=utf-8"); err != nil {
+		t.Fatalf("AddExtensionType failed: %v", err)
+	}
+	if got := TypeByExtension(".MIMEPARSE-TEST"); got != "application/vnd.mimeparse.test+json" {
+		t.Errorf("Custom mapping not found, got %s", got)
+	}
+	exts, _ := ExtensionsByType("application/vnd.mimeparse.test+json")
+	if !reflect.DeepEqual(exts, []string{".mimeparse-test"}) {
+		t.Errorf("Custom mapping not listed, got %v", exts)
+	}
+	for _, bad := range [][2]string{{".x", "image/*"}, {".x", "bogus"}, {"", "text/plain"}} {
+		if err := AddExtensionType(bad[0], bad[1]); err == nil {
+			t.Errorf("AddExtensionType(%s, %s) should fail", bad[0], bad[1])
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package mimeparse
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Returns the value of the parameter 'key', decoding the extended
+// notation of RFC 2231 and RFC 5987 when it is used. For example both
+//
+//	attachment; filename*=UTF-8''na%C3%AFve.txt
+//	attachment; filename*0*=UTF-8''na%C3%AF; filename*1=ve.txt
+//
+// give 'naïve.txt' for the key 'filename'. An extended value takes
+// precedence over a plain 'key' parameter, which is used as a fallback
+// when no extended value is present or it cannot be decoded. The
+// charsets UTF-8, ISO-8859-1 and US-ASCII are supported.
+func (m Mime) DecodedParam(key string) (value string, ok bool) {
+	key = strings.ToLower(key)
+	if v, found := m.params.get(key + "*"); found {
+		if decoded, err := decodeExtValue(v, true); err == nil {
+			return decoded, true
+		}
+	}
+	if decoded, err := m.decodeContinuations(key); err == nil {
+		return decoded, true
+	}
+	value, ok = m.params.get(key)
+	return
+}
+
+// Joins the RFC 2231 continuations 'key*0', 'key*1', ... of a
+// parameter, each of which may itself be extended ('key*1*').
+func (m Mime) decodeContinuations(key string) (string, os.Error) {
+	raw := new(bytes.Buffer)
+	charset := ""
+	for n := 0; ; n++ {
+		name := key + "*" + strconv.Itoa(n)
+		if v, ok := m.params.get(name + "*"); ok {
+			if n == 0 {
+				var err os.Error
+				if charset, v, err = splitExtValue(v); err != nil {
+					return "", err
+				}
+			}
+			decoded, err := percentDecode(v)
+			if err != nil {
+				return "", err
+			}
+			raw.WriteString(decoded)
+		} else if v, ok := m.params.get(name); ok {
+			raw.WriteString(v)
+		} else if n == 0 {
+			return "", os.NewError("No continuations for parameter " + key)
+		} else {
+			break
+		}
+	}
+	if charset == "" {
+		return raw.String(), nil
+	}
+	return decodeCharset(charset, raw.String())
+}
+
+// Decodes an RFC 5987 ext-value such as UTF-8'en'%C2%A3%20rates.
+// If 'hasCharset' is false the value is only percent-decoded.
+func decodeExtValue(v string, hasCharset bool) (string, os.Error) {
+	charset := ""
+	if hasCharset {
+		var err os.Error
+		if charset, v, err = splitExtValue(v); err != nil {
+			return "", err
+		}
+	}
+	decoded, err := percentDecode(v)
+	if err != nil {
+		return "", err
+	}
+	if charset == "" {
+		return decoded, nil
+	}
+	return decodeCharset(charset, decoded)
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// Encodes 's' as an RFC 5987 ext-value in UTF-8, percent-encoding
+// every byte that is not an attr-char.
+func encodeExtValue(s string) string {
+	b := bytes.NewBufferString("UTF-8''")
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c != '%' && c != '\'' && c != '*' && isTokenChar(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&15])
+		}
+	}
+	return b.String()
+}
+
+// Splits charset'language'value, returning the charset and the
+// still encoded value. The language tag is not used.
+func splitExtValue(v string) (charset, value string, err os.Error) {
+	parts := strings.Split(v, "'", 3)
+	if len(parts) != 3 || parts[0] == "" {
+		return "", "", os.NewError("Malformed extended parameter value")
+	}
+	return parts[0], parts[2], nil
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Replaces every %XX with the byte it encodes.
+func percentDecode(s string) (string, os.Error) {
+	b := new(bytes.Buffer)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", os.NewError("Truncated percent-encoding")
+		}
+		hi, ok1 := unhex(s[i+1])
+		lo, ok2 := unhex(s[i+2])
+		if !ok1 || !ok2 {
+			return "", os.NewError("Invalid percent-encoding")
+		}
+		b.WriteByte(hi<<4 | lo)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// Converts raw bytes in the named charset to UTF-8.
+func decodeCharset(charset, raw string) (string, os.Error) {
+	switch NormalizeCharset(charset) {
+	case "utf-8":
+		return raw, nil
+	case "us-ascii":
+		for i := 0; i < len(raw); i++ {
+			if raw[i] >= 0x80 {
+				return "", os.NewError("Invalid US-ASCII in extended parameter")
+			}
+		}
+		return raw, nil
+	case "iso-8859-1":
+		b := new(bytes.Buffer)
+		for i := 0; i < len(raw); i++ {
+			if c := raw[i]; c < 0x80 {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte(0xc0 | c>>6)
+				b.WriteByte(0x80 | c&0x3f)
+			}
+		}
+		return b.String(), nil
+	}
+	return "", os.NewError("Unsupported charset " + charset)
+}
@@ -0,0 +1,35 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestDecodedParam(t *testing.T) {
+	cond := []struct {
+		mime, key, value string
+		ok               bool
+	}{
+		{`attachment/x; filename*=UTF-8''na%C3%AFve.txt`, "filename", "naïve.txt", true},
+		{`attachment/x; filename*=utf-8'en'%E2%82%AC%20rates`, "Filename", "€ rates", true},
+		{`attachment/x; filename*=iso-8859-1''caf%E9`, "filename", "café", true},
+		{`attachment/x; filename="plain.txt"; filename*=UTF-8''better.txt`, "filename", "better.txt", true},
+		{`attachment/x; filename="plain.txt"; filename*=KOI8-R''%C1`, "filename", "plain.txt", true},
+		{`attachment/x; filename="plain.txt"; filename*=UTF-8''bad%2`, "filename", "plain.txt", true},
+		{`attachment/x; filename*0*=UTF-8''na%C3%AF; filename*1=ve; filename*2*=%2Etxt`, "filename", "naïve.txt", true},
+		{`attachment/x; title*0="a;b"; title*1=c`, "title", "a;bc", true},
+		{`attachment/x; filename=plain.txt`, "filename", "plain.txt", true},
+		{`attachment/x; name=x`, "filename", "", false},
+		{`attachment/x; filename*=US-ASCII''%FF`, "filename", "", false},
+	}
+	for _, c := range cond {
+		m, err := ParseMimeType(c.mime)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", c.mime, err)
+			continue
+		}
+		value, ok := m.DecodedParam(c.key)
+		if value != c.value || ok != c.ok {
+			t.Errorf("DecodedParam(%s) of %s == %s, %v; not %s, %v", c.key, c.mime, value, ok, c.value, c.ok)
+		}
+	}
+}
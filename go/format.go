@@ -0,0 +1,165 @@
+package mimeparse
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Writes type/subtype followed by the named parameters.
+func formatMime(m Mime, keys []string) string {
+	b := new(bytes.Buffer)
+	b.WriteString(m.mtype)
+	b.WriteString("/")
+	b.WriteString(m.subtype)
+	for _, k := range keys {
+		b.WriteString(";")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(quoteIfNeeded(m.params.value(k)))
+	}
+	return b.String()
+}
+
+// Serializes the mime-type with its parameters, 'q' included,
+// in the order they were parsed or set. Values that are not
+// tokens are written as quoted-strings.
+func (m Mime) String() string {
+	return formatMime(m, m.params.keys())
+}
+
+// Rewrites a mime-type into a canonical form: type, subtype and
+// parameter names lower-cased, optional whitespace removed and the
+// parameters sorted by name. Two mime-types that differ only in
+// those respects canonicalize to the same string, which makes the
+// result suitable for comparisons and cache keys. For example:
+//
+// Canonicalize('Text/HTML; Level=1; charset=UTF-8')
+// 'text/html;charset=UTF-8;level=1'
+func Canonicalize(mimetype string) (string, os.Error) {
+	parsed, err := ParseMimeType(mimetype)
+	if err != nil {
+		return "", err
+	}
+	keys := parsed.params.keys()
+	sort.SortStrings(keys)
+	return formatMime(parsed, keys), nil
+}
+
+// Formats a media type and parameters like mime.FormatMediaType():
+// the type and parameter names are lower-cased, the parameters
+// sorted by name, and a value quoted only when it is not a token.
+// A value with non-ASCII or control characters is written in the
+// extended notation of RFC 5987, which DecodedParam()
+// reads back. Returns "" if the type is not of the form type/subtype
+// or a name is not a token. Parsing the result with ParseMimeType()
+// gives back the type and, through DecodedParam(), the parameters.
+//
+// FormatMediaType('Text/HTML', {'charset': 'utf-8', 'title': 'a b'})
+// 'text/html;charset=utf-8;title="a b"'
+func FormatMediaType(mediatype string, params map[string]string) string {
+	list := strings.Split(strings.ToLower(mediatype), "/", -1)
+	if len(list) != 2 || !isToken(list[0]) || !isToken(list[1]) {
+		return ""
+	}
+	b := bytes.NewBufferString(list[0] + "/" + list[1])
+	keys := []string{}
+	values := make(map[string]string)
+	for k, v := range params {
+		if !isToken(k) {
+			return ""
+		}
+		k = strings.ToLower(k)
+		keys = append(keys, k)
+		values[k] = v
+	}
+	sort.SortStrings(keys)
+	for _, k := range keys {
+		b.WriteString(";")
+		b.WriteString(k)
+		if v := values[k]; needsExtValue(v) {
+			b.WriteString("*=")
+			b.WriteString(encodeExtValue(v))
+		} else {
+			b.WriteString("=")
+			b.WriteString(quoteIfNeeded(v))
+		}
+	}
+	return b.String()
+}
+
+// Reports whether 's' cannot be written as a quoted-string, having
+// control characters other than tab or bytes outside ASCII.
+func needsExtValue(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < ' ' && c != '\t') || c >= 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// Formats media-ranges as the value of an Accept header, keeping
+// their order. The 'q' of a range is written with at most three
+// decimals and left out when it is 1, unless accept-ext parameters
+// follow it. Values that are not tokens are written as quoted-strings.
+// For example ranges parsed from 'text/html;q=1, text/*;q=0.5' give:
+//
+// 'text/html, text/*;q=0.5'
+func FormatHeader(ranges []Mime) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		s := formatMime(r, r.ParamKeys())
+		q := math.Floor(r.Q()*1000+0.5) / 1000
+		ext := []string{}
+		for _, p := range r.params.pairs {
+			if r.ext[p.key] {
+				ext = append(ext, p.key)
+			}
+		}
+		if q != 1 || len(ext) > 0 {
+			s += ";q=" + strconv.Ftoa64(q, 'f', -1)
+			for _, k := range ext {
+				s += ";" + k + "=" + quoteIfNeeded(r.params.value(k))
+			}
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Formats the media types a resource accepts in request bodies as the
+// value of an Accept-Patch (RFC 5789) or Accept-Post header. Any 'q'
+// and accept-ext parameters are dropped, and of types that differ
+// only in parameter order the first is kept.
+func FormatAcceptPatch(types []Mime) string {
+	seen := make(map[string]bool)
+	parts := []string{}
+	for _, m := range types {
+		if key := rangeKey(m); !seen[key] {
+			seen[key] = true
+			parts = append(parts, formatMime(m, m.ParamKeys()))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Like FormatAcceptPatch() but parses the types first, returning
+// an error for the first one that is not a valid mime-type.
+//
+// FormatAcceptPatchTypes(['text/plain;q=0.5', 'application/json', 'Text/Plain'])
+// 'text/plain, application/json'
+func FormatAcceptPatchTypes(types []string) (string, os.Error) {
+	parsed := make([]Mime, len(types))
+	for i, t := range types {
+		m, err := ParseMimeType(t)
+		if err != nil {
+			return "", err
+		}
+		parsed[i] = m
+	}
+	return FormatAcceptPatch(parsed), nil
+}
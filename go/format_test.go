@@ -0,0 +1,109 @@
+package mimeparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	cond := map[string]string{
+		"Text/HTML; level=1; q=0.5": "text/html;level=1;q=0.5",
+		"application/json":          "application/json",
+		"*":                         "*/*",
+	}
+	for in, out := range cond {
+		m, _ := ParseMimeType(in)
+		if m.String() != out {
+			t.Errorf("String() of %s == %s, not %s", in, m.String(), out)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	cond := map[string]string{
+		"Text/HTML; Level=1; charset=UTF-8":        "text/html;charset=UTF-8;level=1",
+		"text/html;charset=UTF-8;level=1":          "text/html;charset=UTF-8;level=1",
+		" text/html ;  level = 1 ; charset=UTF-8 ": "text/html;charset=UTF-8;level=1",
+		"application/json":                         "application/json",
+	}
+	for in, out := range cond {
+		c, err := Canonicalize(in)
+		if err != nil || c != out {
+			t.Errorf("Canonicalize(%s) == %s, %v, not %s", in, c, err, out)
+		}
+	}
+	if _, err := Canonicalize("nonsense"); err == nil {
+		t.Errorf("Canonicalize should fail on an invalid mime-type")
+	}
+}
+
+func TestFormatMediaType(t *testing.T) {
+	cond := []struct {
+		mediatype string
+		params    map[string]string
+		expected  string
+	}{
+		{"Text/HTML", nil, "text/html"},
+		{"text/html", map[string]string{"Level": "1", "charset": "utf-8"}, "text/html;charset=utf-8;level=1"},
+		{"text/plain", map[string]string{"title": "a \"b\" c\\d"}, "text/plain;title=\"a \\\"b\\\" c\\\\d\""},
+		{"text/plain", map[string]string{"empty": ""}, "text/plain;empty=\"\""},
+		{"attachment/x", map[string]string{"filename": "naïve.txt"}, "attachment/x;filename*=UTF-8''na%C3%AFve.txt"},
+		{"text/plain", map[string]string{"tab": "a\tb", "nl": "a\nb"}, "text/plain;nl*=UTF-8''a%0Ab;tab=\"a\tb\""},
+		{"text", nil, ""},
+		{"text/html;q=1", nil, ""},
+		{"text/html", map[string]string{"bad key": "x"}, ""},
+	}
+	for _, c := range cond {
+		result := FormatMediaType(c.mediatype, c.params)
+		if result != c.expected {
+			t.Errorf("FormatMediaType(%s, %v) == %s, not %s", c.mediatype, c.params, result, c.expected)
+		}
+		if result == "" {
+			continue
+		}
+		m, err := ParseMimeType(result)
+		if err != nil || m.Type()+"/"+m.Subtype() != strings.ToLower(c.mediatype) {
+			t.Errorf("ParseMimeType(%s) == %v, %v", result, m, err)
+		}
+		for k, v := range c.params {
+			if decoded, ok := m.DecodedParam(k); !ok || decoded != v {
+				t.Errorf("DecodedParam(%s) of %s == %s, not %s", k, result, decoded, v)
+			}
+		}
+	}
+}
+
+func TestFormatAcceptPatch(t *testing.T) {
+	cond := map[string][]string{
+		"text/plain, application/json":          {"text/plain;q=0.5", "application/json", "Text/Plain"},
+		`application/ld+json;profile="a b";x=1`: {`application/ld+json; profile="a b"; x=1`, "application/ld+json;x=1;profile=\"a b\""},
+		"text/html;level=1, text/html":          {"text/html;level=1", "text/html"},
+		"":                                      {},
+	}
+	for out, in := range cond {
+		if got, err := FormatAcceptPatchTypes(in); err != nil || got != out {
+			t.Errorf("FormatAcceptPatchTypes(%v) == %s, %v; not %s", in, got, err, out)
+		}
+	}
+	if _, err := FormatAcceptPatchTypes([]string{"text/plain", "bogus"}); err == nil {
+		t.Errorf("FormatAcceptPatchTypes() of an invalid type should fail")
+	}
+}
+
+func TestFormatHeader(t *testing.T) {
+	cond := map[string]string{
+		"text/html;q=1, text/*;q=0.5":                      "text/html, text/*;q=0.5",
+		"Text/HTML;Level=1;q=0.12345":                      "text/html;level=1;q=0.123",
+		`application/ld+json;profile="a b", */*;q=0`:       `application/ld+json;profile="a b", */*;q=0`,
+		"text/plain;q=1;ext=x, image/png":                  "text/plain;q=1;ext=x, image/png",
+		"application/json;charset=utf-8;q=0.8;a;b=\"c d\"": `application/json;charset=utf-8;q=0.8;a="";b="c d"`,
+	}
+	for header, out := range cond {
+		if got := FormatHeader(ParseHeader(header)); got != out {
+			t.Errorf("FormatHeader(%s) == %s, not %s", header, got, out)
+		}
+		if got := FormatHeader(ParseHeader(out)); got != out {
+			t.Errorf("FormatHeader(%s) == %s, not stable", out, got)
+		}
+	}
+}
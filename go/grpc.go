@@ -0,0 +1,55 @@
+package mimeparse
+
+// The media types of gRPC and gRPC-Web. Without a suffix the
+// messages are protocol buffers, so 'application/grpc' and
+// 'application/grpc+proto' are the same type.
+const (
+	ApplicationGRPC             = "application/grpc"
+	ApplicationGRPCProto        = "application/grpc+proto"
+	ApplicationGRPCJSON         = "application/grpc+json"
+	ApplicationGRPCWeb          = "application/grpc-web"
+	ApplicationGRPCWebProto     = "application/grpc-web+proto"
+	ApplicationGRPCWebJSON      = "application/grpc-web+json"
+	ApplicationGRPCWebText      = "application/grpc-web-text"
+	ApplicationGRPCWebTextProto = "application/grpc-web-text+proto"
+)
+
+// The subtypes of the gRPC protocols, without a codec suffix.
+var grpcProtocols = map[string]bool{"grpc": true, "grpc-web": true, "grpc-web-text": true}
+
+// Reports whether the mime-type is gRPC, gRPC-Web or its base64
+// text variant, with any message codec.
+func (m Mime) IsGRPC() bool {
+	return m.mtype == "application" && grpcProtocols[m.BaseSubtype()]
+}
+
+// Returns the gRPC protocol of the mime-type, one of 'grpc',
+// 'grpc-web' and 'grpc-web-text', or "" if it is not gRPC.
+func (m Mime) GRPCProtocol() string {
+	if !m.IsGRPC() {
+		return ""
+	}
+	return m.BaseSubtype()
+}
+
+// Returns the message codec of a gRPC mime-type, such as 'json' for
+// 'application/grpc+json', or 'proto' if the subtype has no suffix.
+// Returns "" if the mime-type is not gRPC.
+func (m Mime) GRPCCodec() string {
+	switch {
+	case !m.IsGRPC():
+		return ""
+	case m.Suffix() == "":
+		return "proto"
+	}
+	return m.Suffix()
+}
+
+// Returns the subtype with the codec of a gRPC type made explicit,
+// so that 'grpc' and 'grpc+proto' compare equal.
+func grpcSubtype(m Mime) string {
+	if m.mtype == "application" && grpcProtocols[m.subtype] {
+		return m.subtype + "+proto"
+	}
+	return m.subtype
+}
@@ -0,0 +1,43 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestGRPC(t *testing.T) {
+	cond := map[string][2]string{
+		"application/grpc":                {"grpc", "proto"},
+		"application/grpc+proto":          {"grpc", "proto"},
+		"application/grpc+json":           {"grpc", "json"},
+		"application/grpc-web":            {"grpc-web", "proto"},
+		"application/grpc-web+json":       {"grpc-web", "json"},
+		"application/grpc-web-text+proto": {"grpc-web-text", "proto"},
+		"application/json":                {"", ""},
+		"text/grpc":                       {"", ""},
+	}
+	for in, out := range cond {
+		m, _ := ParseMimeType(in)
+		if m.GRPCProtocol() != out[0] || m.GRPCCodec() != out[1] || m.IsGRPC() != (out[0] != "") {
+			t.Errorf("GRPC of %s == %s, %s; not %v", in, m.GRPCProtocol(), m.GRPCCodec(), out)
+		}
+	}
+}
+
+func TestGRPCMatching(t *testing.T) {
+	supported := []string{ApplicationGRPCJSON, ApplicationGRPCProto, ApplicationGRPCWeb}
+	bestMatch(t, supported, map[string]string{
+		"application/grpc":                       ApplicationGRPCProto,
+		"application/grpc+json":                  ApplicationGRPCJSON,
+		"application/grpc-web+proto":             ApplicationGRPCWeb,
+		"application/grpc-web-text":              "",
+		"application/*+json":                     ApplicationGRPCJSON,
+		"application/grpc+json;q=0.5, */*;q=0.9": ApplicationGRPCProto,
+	})
+	m, _ := BestMatchRange([]string{ApplicationGRPC}, "application/grpc+proto")
+	if m.Specificity != ExactMatch {
+		t.Errorf("Specificity of grpc+proto for grpc == %v", m.Specificity)
+	}
+	if ok, _, _ := IsContentTypeAccepted("application/grpc", []string{ApplicationGRPCProto}); !ok {
+		t.Errorf("IsContentTypeAccepted(application/grpc) == false")
+	}
+}
@@ -0,0 +1,193 @@
+package mimeparse
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"http"
+	"json"
+	"os"
+	"strings"
+)
+
+// Returns the Accept header of a request, or '*/*' if the request
+// has none or it is empty, which RFC 7231 says means that any
+// media type is acceptable. The values of a header that appears on
+// several lines are joined, dropping empty list elements.
+func requestAccept(h http.Header) string {
+	// A common header is passed on verbatim, as it was parsed in
+	// advance, see commonHeaders.
+	if values := h["Accept"]; len(values) == 1 {
+		if _, ok := commonHeaders[values[0]]; ok {
+			return values[0]
+		}
+	}
+	header := strings.Join(ParseList(strings.Join(h["Accept"], ",")), ", ")
+	if header == "" {
+		return "*/*"
+	}
+	return header
+}
+
+// Parses the Accept header of a request with ParseHeader(), joining
+// the values of an Accept header that appears on several lines. A
+// missing or empty header gives the single range '*/*'.
+func ParseAcceptHeader(h http.Header) []Mime {
+	return defaultPolicy.ParseAcceptHeader(h)
+}
+
+// Like ParseAcceptHeader() but applying the options of the policy.
+func (p *Policy) ParseAcceptHeader(h http.Header) []Mime {
+	return p.ParseHeader(requestAccept(h))
+}
+
+// Negotiates the type of the response to a request, like
+// NegotiateType(). A request without an Accept header, or with an
+// empty one, accepts any type and gets the first supported type.
+// ErrNotAcceptable is only returned for an Accept header that is
+// present but matches none of the supported types, which calls
+// for a 406 Not Acceptable response.
+func NegotiateRequest(supported []string, req *http.Request) (string, os.Error) {
+	return defaultPolicy.NegotiateRequest(supported, req)
+}
+
+// Like NegotiateRequest() but applying the options of the policy.
+func (p *Policy) NegotiateRequest(supported []string, req *http.Request) (string, os.Error) {
+	return p.NegotiateType(supported, requestAccept(req.Header))
+}
+
+// Returned by IsContentTypeAccepted() when a request body has a type
+// the server does not accept, in which case it should respond with
+// 415 Unsupported Media Type.
+var ErrUnsupportedMediaType = os.NewError("Unsupported media type")
+
+// Checks the Content-Type of a request body against the types a
+// handler accepts, which may be ranges such as 'text/*' or
+// 'application/*+json'. Here the wildcards are on the server's side:
+// 'text/*' accepts a body of 'text/plain' but a body with a wildcard
+// type is never accepted. Parameters of an accepted type, such as
+// 'charset=utf-8', must be present in the Content-Type. Returns the
+// parsed Content-Type along with whether it is accepted, and an error
+// if it is malformed or missing, or ErrUnsupportedMediaType if it is
+// not accepted.
+func IsContentTypeAccepted(contentType string, accepted []string) (bool, Mime, os.Error) {
+	return defaultPolicy.IsContentTypeAccepted(contentType, accepted)
+}
+
+// Like IsContentTypeAccepted() but applying the options of the policy.
+func (p *Policy) IsContentTypeAccepted(contentType string, accepted []string) (bool, Mime, os.Error) {
+	mime, err := p.ParseMimeType(contentType)
+	if err != nil {
+		return false, mime, err
+	}
+	if !mime.IsConcrete() {
+		return false, mime, os.NewError("Content-Type is not a concrete media type")
+	}
+	for _, a := range accepted {
+		r, err := p.ParseMediaRange(a)
+		if err == nil && p.rangeFitness(mime, r) >= 0 {
+			return true, mime, nil
+		}
+	}
+	return false, mime, ErrUnsupportedMediaType
+}
+
+// Types whose charset is fixed by their definition, whatever their
+// charset parameter says.
+var fixedCharsets = map[string]string{
+	"application/json":  "utf-8",
+	"text/event-stream": "utf-8",
+}
+
+// Parses the Content-Type of a request body, returning the charset
+// the body is encoded in along with the type. The charset comes from
+// the charset parameter, normalized with NormalizeCharset(), or else
+// from the default of the type: 'utf-8' for JSON, including types
+// with the '+json' suffix, whose charset is always UTF-8, and
+// 'us-ascii' for 'text' types, see Policy.TextCharset. The charset is
+// empty for other types without a charset parameter, such as
+// 'image/png' or 'application/xml' whose encoding is given by the
+// document itself. A multipart type
+// must carry a valid boundary, see Mime.Boundary().
+//
+// ParseContentType('text/plain')
+// 'us-ascii'
+// ParseContentType('application/json; charset=latin1')
+// 'utf-8'
+func ParseContentType(value string) (mime Mime, charset string, err os.Error) {
+	return defaultPolicy.ParseContentType(value)
+}
+
+// Like ParseContentType() but applying the options of the policy.
+func (p *Policy) ParseContentType(value string) (mime Mime, charset string, err os.Error) {
+	if mime, err = p.ParseMimeType(value); err != nil {
+		return mime, "", err
+	}
+	if !mime.IsConcrete() {
+		return mime, "", os.NewError("Content-Type is not a concrete media type")
+	}
+	if mime.IsMultipart() {
+		if _, err = mime.Boundary(); err != nil {
+			return mime, "", err
+		}
+	}
+	if c, ok := fixedCharsets[mime.mtype+"/"+mime.subtype]; ok {
+		return mime, c, nil
+	}
+	if mime.Suffix() == "json" {
+		return mime, "utf-8", nil
+	}
+	if c, ok := mime.params.get("charset"); ok {
+		return mime, NormalizeCharset(c), nil
+	}
+	if mime.mtype == "text" {
+		charset = p.TextCharset
+		if charset == "" {
+			charset = "us-ascii"
+		}
+	}
+	return mime, charset, nil
+}
+
+// Responds to a request with 406 Not Acceptable, listing the supported
+// types in the body so a client can retry with a suitable Accept
+// header. The body is itself negotiated: an HTML page for clients that
+// prefer 'text/html', such as browsers, otherwise an RFC 7807 problem
+// document of type 'application/problem+json' with the types in a
+// 'supported' member.
+func NotAcceptable(w http.ResponseWriter, req *http.Request, supported []string) {
+	addVary(w.Header(), "Accept")
+	body := new(bytes.Buffer)
+	switch BestMatchWithDefault([]string{ApplicationProblemJSON, TextHTML}, requestAccept(req.Header), ApplicationProblemJSON) {
+	case TextHTML:
+		w.Header().Set("Content-Type", TextHTMLUTF8)
+		body.WriteString("<!DOCTYPE html>\n<title>406 Not Acceptable</title>\n<h1>Not Acceptable</h1>\n")
+		body.WriteString("<p>None of the available representations is acceptable. Available types:</p>\n<ul>\n")
+		for _, s := range supported {
+			fmt.Fprintf(body, "<li>%s</li>\n", html.EscapeString(s))
+		}
+		body.WriteString("</ul>\n")
+	default:
+		w.Header().Set("Content-Type", ApplicationProblemJSON)
+		problem, _ := json.Marshal(map[string]interface{}{
+			"type":      "about:blank",
+			"title":     "Not Acceptable",
+			"status":    http.StatusNotAcceptable,
+			"detail":    "None of the available representations is acceptable.",
+			"supported": supported,
+		})
+		body.Write(problem)
+	}
+	w.WriteHeader(http.StatusNotAcceptable)
+	w.Write(body.Bytes())
+}
+
+// Adds a header name to the Vary header unless it is already listed.
+func addVary(h http.Header, name string) {
+	for _, v := range ParseList(strings.Join(h["Vary"], ",")) {
+		if v == "*" || strings.ToLower(v) == strings.ToLower(name) {
+			return
+		}
+	}
+	h.Add("Vary", name)
+}
@@ -0,0 +1,152 @@
+package mimeparse
+
+import (
+	"http"
+	"http/httptest"
+	"json"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateRequest(t *testing.T) {
+	supported := []string{"application/json", "text/html"}
+	cond := []struct {
+		accept []string
+		mime   string
+		err    bool
+	}{
+		{nil, "application/json", false},
+		{[]string{""}, "application/json", false},
+		{[]string{" , "}, "application/json", false},
+		{[]string{"text/html"}, "text/html", false},
+		{[]string{"image/png", "text/*;q=0.5"}, "text/html", false},
+		{[]string{"image/png"}, "", true},
+		{[]string{"application/json;q=0, text/html;q=0"}, "", true},
+	}
+	for _, c := range cond {
+		req := &http.Request{Header: http.Header{}}
+		if c.accept != nil {
+			req.Header["Accept"] = c.accept
+		}
+		mime, err := NegotiateRequest(supported, req)
+		if mime != c.mime || (err != nil) != c.err {
+			t.Errorf("NegotiateRequest(%v) == %s, %v; not %s", c.accept, mime, err, c.mime)
+		}
+		if c.err && err != ErrNotAcceptable {
+			t.Errorf("NegotiateRequest(%v) should fail with ErrNotAcceptable, got %v", c.accept, err)
+		}
+	}
+}
+
+func TestIsContentTypeAccepted(t *testing.T) {
+	accepted := []string{"application/json", "text/*", "application/*+xml", "multipart/form-data"}
+	cond := []struct {
+		contentType string
+		ok, err     bool
+	}{
+		{"application/json", true, false},
+		{"Application/JSON; charset=utf-8", true, false},
+		{"text/plain", true, false},
+		{"application/atom+xml", true, false},
+		{"multipart/form-data; boundary=xyz", true, false},
+		{"application/xml", false, true},
+		{"image/png", false, true},
+		{"text/*", false, true},
+		{"", false, true},
+	}
+	for _, c := range cond {
+		ok, m, err := IsContentTypeAccepted(c.contentType, accepted)
+		if ok != c.ok || (err != nil) != c.err {
+			t.Errorf("IsContentTypeAccepted(%s) == %v, %v, %v", c.contentType, ok, m, err)
+		}
+	}
+	if _, _, err := IsContentTypeAccepted("image/png", accepted); err != ErrUnsupportedMediaType {
+		t.Errorf("IsContentTypeAccepted(image/png) error == %v", err)
+	}
+	if ok, _, _ := IsContentTypeAccepted("text/plain", []string{"text/plain;charset=utf-8"}); ok {
+		t.Errorf("IsContentTypeAccepted() without the required charset == true")
+	}
+}
+
+func TestParseContentType(t *testing.T) {
+	cond := map[string]string{
+		"text/plain":                          "us-ascii",
+		"Text/HTML; Charset=UTF-8":            "utf-8",
+		"application/json":                    "utf-8",
+		"application/json; charset=latin1":    "utf-8",
+		"application/vnd.api+json":            "utf-8",
+		"text/event-stream":                   "utf-8",
+		"application/xml":                     "",
+		"application/xml; charset=ISO-8859-1": "iso-8859-1",
+		"image/png":                           "",
+		"text/plain; charset=Latin1":          "iso-8859-1",
+		"multipart/form-data; boundary=xyz":   "",
+	}
+	for value, expected := range cond {
+		mime, charset, err := ParseContentType(value)
+		if err != nil || charset != expected {
+			t.Errorf("ParseContentType(%s) == %v, %s, %v, not %s", value, mime, charset, err, expected)
+		}
+	}
+	p := &Policy{TextCharset: "utf-8"}
+	if _, charset, _ := p.ParseContentType("text/plain"); charset != "utf-8" {
+		t.Errorf("ParseContentType(text/plain) with TextCharset == %s", charset)
+	}
+	for _, value := range []string{"", "text/*", "*/*", "multipart/mixed", "multipart/mixed; boundary=\"a b \""} {
+		if _, _, err := ParseContentType(value); err == nil {
+			t.Errorf("ParseContentType(%s) did not fail", value)
+		}
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	supported := []string{"application/json", "text/csv;header=<present>"}
+	cond := map[string]string{
+		"":          ApplicationProblemJSON,
+		"image/png": ApplicationProblemJSON,
+		"text/html,application/xml;q=0.9,*/*;q=0.8": TextHTMLUTF8,
+		"application/problem+json, text/html;q=0.5": ApplicationProblemJSON,
+	}
+	for accept, contentType := range cond {
+		req := &http.Request{Header: http.Header{}}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		NotAcceptable(w, req, supported)
+		if w.Code != http.StatusNotAcceptable || w.Header().Get("Content-Type") != contentType || w.Header().Get("Vary") != "Accept" {
+			t.Errorf("NotAcceptable(%s) == %d, %v", accept, w.Code, w.Header())
+		}
+		body := w.Body.String()
+		if contentType == TextHTMLUTF8 {
+			if !strings.Contains(body, "<li>text/csv;header=&lt;present&gt;</li>") {
+				t.Errorf("NotAcceptable(%s) body == %s", accept, body)
+			}
+			continue
+		}
+		var problem map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem["status"] != float64(406) || len(problem["supported"].([]interface{})) != 2 {
+			t.Errorf("NotAcceptable(%s) body == %s, %v", accept, body, err)
+		}
+	}
+}
+
+func TestParseAcceptHeader(t *testing.T) {
+	h := http.Header{}
+	h.Add("Accept", "text/html, application/xhtml+xml")
+	h.Add("Accept", "application/xml;q=0.9")
+	h.Add("Accept", "")
+	h.Add("Accept", "*/*;q=0.8")
+	got := FormatHeader(ParseAcceptHeader(h))
+	if want := "text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8"; got != want {
+		t.Errorf("ParseAcceptHeader(%v) == %s, not %s", h, got, want)
+	}
+	if got := FormatHeader(ParseAcceptHeader(http.Header{})); got != "*/*" {
+		t.Errorf("ParseAcceptHeader() without Accept == %s", got)
+	}
+	p := &Policy{MergeDuplicates: true}
+	h = http.Header{"Accept": {"text/html;q=0.5", "text/html"}}
+	if got := FormatHeader(p.ParseAcceptHeader(h)); got != "text/html" {
+		t.Errorf("MergeDuplicates ParseAcceptHeader(%v) == %s", h, got)
+	}
+}
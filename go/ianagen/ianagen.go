@@ -0,0 +1,192 @@
+// Ianagen downloads the IANA media type registries and writes the
+// Go tables used by mimeparse.ValidateRegistered.
+//
+// Usage:
+//
+//	ianagen [-o ianatypes.go] [-registry url] [-provisional url] [-suffix url]
+//
+// The registries are fetched from IANA unless a mirror is given.
+package main
+
+import (
+	"bytes"
+	"csv"
+	"flag"
+	"fmt"
+	"http"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+const registryURL = "http://www.iana.org/assignments/media-types/"
+const provisionalURL = "http://www.iana.org/assignments/provisional-standard-media-types/"
+const suffixURL = "http://www.iana.org/assignments/media-type-structured-suffix/"
+
+var topLevel = []string{
+	"application", "audio", "font", "haptics", "image", "message",
+	"model", "multipart", "text", "video",
+}
+
+var (
+	output          = flag.String("o", "ianatypes.go", "file to write the tables to")
+	registryBase    = flag.String("registry", registryURL, "URL of the directory of media type CSVs")
+	provisionalBase = flag.String("provisional", provisionalURL, "URL of the directory of the provisional CSV")
+	suffixBase      = flag.String("suffix", suffixURL, "URL of the directory of the structured suffix CSV")
+)
+
+// Fetches a registry CSV and returns its rows, checking that the
+// named columns are present. The map gives the index of each.
+func fetchCSV(url string, columns ...string) ([][]string, map[string]int, os.Error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, nil, os.NewError(url + ": " + resp.Status)
+	}
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) < 1 {
+		return nil, nil, os.NewError(url + ": empty registry")
+	}
+	index := make(map[string]int)
+	for i, h := range records[0] {
+		index[strings.TrimSpace(h)] = i
+	}
+	for _, c := range columns {
+		if _, ok := index[c]; !ok {
+			return nil, nil, os.NewError(url + ": missing " + c + " column")
+		}
+	}
+	return records[1:], index, nil
+}
+
+// Fetches a registry CSV and returns the lower-cased media types it
+// lists. A row without a template gives the type 'top/Name', unless
+// 'top' is "" or the name has spaces, as those of deprecated and
+// obsoleted entries do ('example - DEPRECATED'), which are skipped.
+func fetch(url, top string) ([]string, os.Error) {
+	records, index, err := fetchCSV(url, "Name", "Template")
+	if err != nil {
+		return nil, err
+	}
+	name, template := index["Name"], index["Template"]
+	types := []string{}
+	for _, r := range records {
+		t := ""
+		if template < len(r) {
+			t = strings.TrimSpace(r[template])
+		}
+		if t == "" && top != "" && name < len(r) && !strings.Contains(r[name], " ") {
+			t = top + "/" + strings.TrimSpace(r[name])
+		}
+		if strings.Count(t, "/") != 1 {
+			continue
+		}
+		types = append(types, strings.ToLower(t))
+	}
+	return types, nil
+}
+
+// Fetches the structured syntax suffix registry and returns its
+// suffixes, lower-cased and without the '+', each followed by the
+// name of the syntax it stands for.
+func fetchSuffixes(url string) ([][2]string, os.Error) {
+	records, index, err := fetchCSV(url, "Suffix String", "Name")
+	if err != nil {
+		return nil, err
+	}
+	suffix, name := index["Suffix String"], index["Name"]
+	suffixes := [][2]string{}
+	for _, r := range records {
+		if suffix >= len(r) || name >= len(r) {
+			continue
+		}
+		s := strings.ToLower(strings.TrimSpace(r[suffix]))
+		if !strings.HasPrefix(s, "+") || len(s) == 1 {
+			continue
+		}
+		suffixes = append(suffixes, [2]string{s[1:], strings.TrimSpace(r[name])})
+	}
+	return suffixes, nil
+}
+
+// Sorts a list of types and removes duplicates.
+func uniq(types []string) []string {
+	sort.SortStrings(types)
+	out := []string{}
+	for i, t := range types {
+		if i == 0 || t != types[i-1] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func writeList(b *bytes.Buffer, name string, types []string) {
+	fmt.Fprintf(b, "var %s = []string{", name)
+	if len(types) > 0 {
+		b.WriteString("\n")
+	}
+	for _, t := range types {
+		fmt.Fprintf(b, "\t%q,\n", t)
+	}
+	b.WriteString("}\n")
+}
+
+func writePairs(b *bytes.Buffer, name string, pairs [][2]string) {
+	fmt.Fprintf(b, "var %s = [][2]string{", name)
+	if len(pairs) > 0 {
+		b.WriteString("\n")
+	}
+	for _, p := range pairs {
+		fmt.Fprintf(b, "\t{%q, %q},\n", p[0], p[1])
+	}
+	b.WriteString("}\n")
+}
+
+func main() {
+	flag.Parse()
+	registered := []string{}
+	for _, top := range topLevel {
+		types, err := fetch(*registryBase+top+".csv", top)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ianagen:", err)
+			os.Exit(1)
+		}
+		registered = append(registered, types...)
+	}
+	provisional, err := fetch(*provisionalBase+"provisional-standard-media-types-1.csv", "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ianagen:", err)
+		os.Exit(1)
+	}
+	suffixes, err := fetchSuffixes(*suffixBase + "media-type-structured-suffix-1.csv")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ianagen:", err)
+		os.Exit(1)
+	}
+
+	b := new(bytes.Buffer)
+	b.WriteString("// Media types listed in the IANA media type registry,\n")
+	b.WriteString("// " + registryURL + "\n")
+	b.WriteString("//\n// Generated by ianagen; DO NOT EDIT.\n\n")
+	b.WriteString("package mimeparse\n\n")
+	writeList(b, "registeredTypes", uniq(registered))
+	b.WriteString("\n// Media types listed in the IANA provisional standard media type registry,\n")
+	b.WriteString("// " + provisionalURL + "\n")
+	writeList(b, "provisionalTypes", uniq(provisional))
+	b.WriteString("\n// Structured syntax suffixes listed in the IANA registry, without\n")
+	b.WriteString("// their '+', and the names of the syntaxes they stand for,\n")
+	b.WriteString("// " + suffixURL + "\n")
+	writePairs(b, "registeredSuffixes", suffixes)
+	if err := ioutil.WriteFile(*output, b.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "ianagen:", err)
+		os.Exit(1)
+	}
+}
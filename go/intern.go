@@ -0,0 +1,37 @@
+package mimeparse
+
+// The type, subtype and parameter names found in most headers. A
+// parsed mime-type refers to these rather than to the text it was
+// parsed from, so that equal tokens share memory and keeping a parsed
+// range does not keep the whole header, or a lower-cased copy of it,
+// alive. It is a composite literal rather than filled in by init(),
+// since the init() of common.go runs first and already parses with it.
+var commonTokens = map[string]string{
+	"*": "*", "application": "application", "audio": "audio",
+	"font": "font", "image": "image", "message": "message",
+	"model": "model", "multipart": "multipart", "text": "text",
+	"video": "video",
+
+	"apng": "apng", "avif": "avif", "css": "css", "csv": "csv",
+	"event-stream": "event-stream", "form-data": "form-data",
+	"gif": "gif", "gzip": "gzip", "html": "html",
+	"javascript": "javascript", "jpeg": "jpeg", "json": "json",
+	"ld+json": "ld+json", "mp4": "mp4", "mpeg": "mpeg",
+	"octet-stream": "octet-stream", "ogg": "ogg", "pdf": "pdf",
+	"plain": "plain", "png": "png", "problem+json": "problem+json",
+	"signed-exchange": "signed-exchange", "svg+xml": "svg+xml",
+	"wasm": "wasm", "webm": "webm",
+	"webp": "webp", "x-www-form-urlencoded": "x-www-form-urlencoded",
+	"xhtml+xml": "xhtml+xml", "xml": "xml", "zip": "zip",
+
+	"boundary": "boundary", "charset": "charset", "level": "level",
+	"profile": "profile", "q": "q", "v": "v", "version": "version",
+}
+
+// Returns the common token equal to 's', or 's' itself.
+func intern(s string) string {
+	if t, ok := commonTokens[s]; ok {
+		return t
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package mimeparse
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+// Reports whether two strings share their bytes.
+func sameStorage(a, b string) bool {
+	ha := (*reflect.StringHeader)(unsafe.Pointer(&a))
+	hb := (*reflect.StringHeader)(unsafe.Pointer(&b))
+	return ha.Data == hb.Data && ha.Len == hb.Len
+}
+
+func TestIntern(t *testing.T) {
+	for s, expected := range map[string]string{
+		"json":      "json",
+		"xhtml+xml": "xhtml+xml",
+		"x-custom":  "x-custom",
+		"JSON":      "JSON",
+		"":          "",
+	} {
+		if result := intern(s); result != expected {
+			t.Errorf("intern(%s) == %s, not %s", s, result, expected)
+		}
+	}
+	header := "Text/HTML;Charset=UTF-8;Q=0.5"
+	m := mustParse(t, header)
+	if m.mtype != "text" || m.subtype != "html" || m.params.keys()[0] != "charset" || m.Q() != 0.5 {
+		t.Errorf("ParseMediaRange(%s) == %v", header, m)
+	}
+	// The parsed tokens are the entries of the table, not copies
+	// lower-cased from the header or slices of it.
+	for _, s := range []string{m.mtype, m.subtype, m.params.keys()[0], m.params.keys()[1]} {
+		if !sameStorage(s, commonTokens[s]) {
+			t.Errorf("ParseMediaRange(%s) did not intern %s", header, s)
+		}
+	}
+	header = "application/json, application/x-custom"
+	parsed := ParseHeader(header)
+	if !sameStorage(parsed[0].subtype, commonTokens["json"]) || !sameStorage(parsed[1].subtype, header[len(header)-8:]) {
+		t.Errorf("ParseHeader(%s) == %v, interning the wrong tokens", header, parsed)
+	}
+	// The common headers are parsed before any request, and must
+	// intern their tokens as well.
+	header = commonHeaderList[2]
+	for _, m := range ParseHeader(header) {
+		for _, s := range append([]string{m.mtype, m.subtype}, m.params.keys()...) {
+			if !sameStorage(s, commonTokens[s]) {
+				t.Errorf("ParseHeader(%s) did not intern %s", header, s)
+			}
+		}
+	}
+}
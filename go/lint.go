@@ -0,0 +1,88 @@
+package mimeparse
+
+import (
+	"strings"
+)
+
+// The kind of problem a Warning reports.
+type WarningKind int
+
+const (
+	ObsoleteType       WarningKind = iota // a deprecated name with a current replacement
+	ExperimentalType                      // an 'x-' name, deprecated by RFC 6648
+	UnregisteredType                      // a type not in the IANA registry
+	UnregisteredSuffix                    // a structured syntax suffix not in the IANA registry
+	IgnoredParameter                      // a parameter the type does not define
+	WildcardType                          // a range where a media type is expected
+)
+
+var warningKindNames = []string{"obsolete", "experimental", "unregistered", "unregistered-suffix", "ignored-parameter", "wildcard"}
+
+func (k WarningKind) String() string {
+	if k < 0 || int(k) >= len(warningKindNames) {
+		return "invalid"
+	}
+	return warningKindNames[k]
+}
+
+// A problem found by Lint().
+type Warning struct {
+	Kind    WarningKind
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Kind.String() + ": " + w.Message
+}
+
+// Types missing from the IANA registry that other standards define,
+// such as the form encoding of HTML, which Lint() accepts as they are.
+var establishedTypes = map[string]bool{
+	"application/x-www-form-urlencoded": true,
+}
+
+// Types whose definitions give them no parameters, although clients
+// often send a charset with them.
+var parameterlessTypes = map[string]bool{
+	"application/x-www-form-urlencoded": true,
+	"application/json":                  true,
+}
+
+// Checks a media type for problems worth fixing in an API
+// specification or in the Content-Type headers a server sends:
+// obsolete names such as 'application/javascript', which RFC 9239
+// replaced with 'text/javascript', 'x-' names, types and suffixes
+// missing from the IANA registries, parameters the type does not
+// define, such as a charset on 'application/x-www-form-urlencoded',
+// and wildcards. Returns an empty list for a type without problems.
+//
+// Lint(ParseMimeType("image/jpg"))
+// [obsolete: image/jpg is obsolete, use image/jpeg]
+func Lint(mime Mime) []Warning {
+	warnings := []Warning{}
+	warn := func(kind WarningKind, message string) {
+		warnings = append(warnings, Warning{kind, message})
+	}
+	name := mime.mtype + "/" + mime.subtype
+	if mime.IsWildcard() {
+		warn(WildcardType, name+" is a range, not a media type")
+		return warnings
+	}
+	registered := ValidateRegistered(mime) != Unknown || establishedTypes[name]
+	if current, ok := aliases[name]; ok {
+		warn(ObsoleteType, name+" is obsolete, use "+current)
+	} else if !registered && (strings.HasPrefix(mime.mtype, "x-") || strings.HasPrefix(mime.subtype, "x-")) {
+		warn(ExperimentalType, name+" uses the 'x-' prefix deprecated by RFC 6648, use the vendor or personal tree")
+	} else if !registered {
+		warn(UnregisteredType, name+" is not registered with IANA")
+	}
+	if !HasRegisteredSuffix(mime) {
+		warn(UnregisteredSuffix, "+"+mime.Suffix()+" is not a registered structured syntax suffix")
+	}
+	if parameterlessTypes[name] {
+		for _, k := range mime.ParamKeys() {
+			warn(IgnoredParameter, name+" defines no parameters, "+k+" is ignored")
+		}
+	}
+	return warnings
+}
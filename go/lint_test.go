@@ -0,0 +1,41 @@
+package mimeparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cond := map[string][]WarningKind{
+		"application/json":                  {},
+		"text/javascript":                   {},
+		"application/vnd.api+json":          {},
+		"application/javascript":            {ObsoleteType},
+		"image/jpg":                         {ObsoleteType},
+		"application/x-foo":                 {ExperimentalType},
+		"x-foo/bar":                         {ExperimentalType},
+		"application/x-www-form-urlencoded": {},
+		"application/x-www-form-urlencoded;charset=utf-8": {IgnoredParameter},
+		"application/json; charset=utf-8":                 {IgnoredParameter},
+		"application/vnd.example.thing":                   {UnregisteredType},
+		"application/vnd.example+protobuf":                {UnregisteredType, UnregisteredSuffix},
+		"text/*":                                          {WildcardType},
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		got := []WarningKind{}
+		for _, w := range Lint(m) {
+			got = append(got, w.Kind)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Lint(%s) == %v, not %v", mime, Lint(m), want)
+		}
+	}
+	m, _ := ParseMimeType("image/jpg")
+	if w := Lint(m)[0].String(); w != "obsolete: image/jpg is obsolete, use image/jpeg" {
+		t.Errorf("Warning.String() == %s", w)
+	}
+	if WarningKind(42).String() != "invalid" {
+		t.Errorf("WarningKind.String() failed")
+	}
+}
@@ -0,0 +1,81 @@
+package mimeparse
+
+import (
+	"os"
+)
+
+// A list of supported types parsed once, for servers that negotiate
+// against the same types on every request. Where BestMatch() parses
+// the supported types anew on each call, a Matcher only parses the
+// header. A Matcher is safe for concurrent use; the policy it was
+// made with must not be changed afterwards.
+//
+// m, _ := NewMatcher(['application/json', 'text/html'])
+// m.Match('text/*;q=0.5, application/json')
+// 'application/json'
+type Matcher struct {
+	policy    *Policy
+	supported []string
+	targets   []Mime
+}
+
+// Parses a list of supported types into a Matcher. Returns an error
+// if one of them is malformed.
+func NewMatcher(supported []string) (*Matcher, os.Error) {
+	return defaultPolicy.NewMatcher(supported)
+}
+
+// Like NewMatcher() but applying the options of the policy.
+func (p *Policy) NewMatcher(supported []string) (*Matcher, os.Error) {
+	m := &Matcher{p, make([]string, len(supported)), make([]Mime, len(supported))}
+	copy(m.supported, supported)
+	for i, s := range supported {
+		if _, err := p.ParseMediaRange(s); err != nil {
+			return nil, os.NewError("Invalid supported type " + s + ": " + err.String())
+		}
+		m.targets[i] = p.parseTarget(s)
+	}
+	return m, nil
+}
+
+// Returns the supported types of the matcher.
+func (m *Matcher) Supported() []string {
+	supported := make([]string, len(m.supported))
+	copy(supported, m.supported)
+	return supported
+}
+
+// Like BestMatch() for the supported types of the matcher.
+func (m *Matcher) Match(header string) string {
+	best, _ := m.MatchRange(header)
+	return best.Type
+}
+
+// Like BestMatchRange() for the supported types of the matcher.
+func (m *Matcher) MatchRange(header string) (Match, bool) {
+	parsed := m.policy.ParseHeader(header)
+	best, ok := m.MatchParsed(parsed)
+	Recycle(parsed)
+	return best, ok
+}
+
+// Like MatchRange() for a header already parsed with ParseHeader().
+func (m *Matcher) MatchParsed(parsedHeader []Mime) (Match, bool) {
+	best, ok := m.policy.bestTargetMatch(m.supported, m.targets, parsedHeader)
+	m.policy.observe(best)
+	return best, ok
+}
+
+// Like NegotiateType() for the supported types of the matcher.
+func (m *Matcher) Negotiate(header string) (string, os.Error) {
+	parsed, err := m.policy.parseHeader(header)
+	if err == ErrTooLarge {
+		return "", err
+	}
+	best, ok := m.MatchParsed(parsed)
+	Recycle(parsed)
+	if ok {
+		return best.Type, nil
+	}
+	return "", ErrNotAcceptable
+}
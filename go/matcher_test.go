@@ -0,0 +1,58 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	supported := []string{"application/xbel+xml", "application/xml", "text/*;q=0.5", "application/*+json"}
+	m, err := NewMatcher(supported)
+	if err != nil {
+		t.Fatalf("NewMatcher() failed: %v", err)
+	}
+	supported[0] = "changed/later"
+	headers := []string{
+		"application/xbel+xml",
+		"application/xml;q=1",
+		"text/html, application/xml;q=0.5",
+		"application/vnd.api+json",
+		"*/*",
+		"image/png",
+		"",
+		"application/*;q=0.5, text/*;q=0.1",
+	}
+	original := []string{"application/xbel+xml", "application/xml", "text/*;q=0.5", "application/*+json"}
+	for _, header := range headers {
+		if got, want := m.Match(header), BestMatch(original, header); got != want {
+			t.Errorf("Match(%s) == %s, not %s", header, got, want)
+		}
+		got, ok := m.MatchRange(header)
+		want, wantOK := BestMatchRange(original, header)
+		if got.Type != want.Type || got.Quality != want.Quality || got.Index() != want.Index() || ok != wantOK {
+			t.Errorf("MatchRange(%s) == %v, %v, not %v, %v", header, got, ok, want, wantOK)
+		}
+	}
+	if s := m.Supported(); s[0] != "application/xbel+xml" {
+		t.Errorf("Supported() == %v", s)
+	}
+	if _, err := m.Negotiate("image/png"); err != ErrNotAcceptable {
+		t.Errorf("Negotiate(image/png) error == %v", err)
+	}
+	if got, err := m.Negotiate("text/plain"); got != "text/*;q=0.5" || err != nil {
+		t.Errorf("Negotiate(text/plain) == %s, %v", got, err)
+	}
+	if _, err := NewMatcher([]string{"application/json", "bogus"}); err == nil {
+		t.Errorf("NewMatcher() with an invalid type did not fail")
+	}
+	p := &Policy{NormalizeAliases: true}
+	if m, _ := p.NewMatcher([]string{"image/jpeg"}); m.Match("image/jpg") != "image/jpeg" {
+		t.Errorf("Matcher should apply the options of its policy")
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	m, _ := NewMatcher([]string{"application/json", "text/html"})
+	for i := 0; i < b.N; i++ {
+		m.Match("text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8")
+	}
+}
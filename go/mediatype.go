@@ -0,0 +1,300 @@
+package mimeparse
+
+import (
+	"os"
+	"strings"
+)
+
+// Common media types, for use in supported lists and Content-Type
+// headers instead of string literals.
+const (
+	ApplicationJSON        = "application/json"
+	ApplicationXML         = "application/xml"
+	ApplicationJavaScript  = "application/javascript"
+	ApplicationOctetStream = "application/octet-stream"
+	ApplicationForm        = "application/x-www-form-urlencoded"
+	ApplicationPDF         = "application/pdf"
+	ApplicationAtomXML     = "application/atom+xml"
+	ApplicationXHTMLXML    = "application/xhtml+xml"
+	ApplicationProblemJSON = "application/problem+json"
+	MultipartFormData      = "multipart/form-data"
+	MultipartMixed         = "multipart/mixed"
+	MultipartAlternative   = "multipart/alternative"
+	MultipartRelated       = "multipart/related"
+	MultipartSigned        = "multipart/signed"
+	MultipartEncrypted     = "multipart/encrypted"
+	MessageRFC822          = "message/rfc822"
+	MessageGlobal          = "message/global"
+	TextHTML               = "text/html"
+	TextHTMLUTF8           = "text/html; charset=utf-8"
+	TextPlain              = "text/plain"
+	TextPlainUTF8          = "text/plain; charset=utf-8"
+	TextCSS                = "text/css"
+	TextCSV                = "text/csv"
+	TextXML                = "text/xml"
+	TextJavaScript         = "text/javascript"
+	ImagePNG               = "image/png"
+	ImageJPEG              = "image/jpeg"
+	ImageGIF               = "image/gif"
+	ImageWebP              = "image/webp"
+	ImageSVGXML            = "image/svg+xml"
+)
+
+// A fully specified mime-type, such as the value of a Content-Type
+// header. A MediaType never contains wildcards or a 'q' parameter.
+// It is read-only, so that nothing can break either rule; use Mime()
+// to get a copy that can be changed.
+type MediaType struct {
+	mime Mime
+}
+
+// A media-range, such as one entry of an Accept header. A MediaRange
+// may contain wildcards and always has a 'q' quality parameter. Like
+// a MediaType it is read-only, see Mime().
+type MediaRange struct {
+	mime Mime
+}
+
+// Parses a mime-type that must be fully specified. For example,
+// 'text/html;charset=utf-8' parses, while 'text/*' is an error.
+// A 'q' parameter, if present, is discarded.
+func ParseMediaType(mediatype string) (MediaType, os.Error) {
+	parsed, err := ParseMimeType(mediatype)
+	if err != nil {
+		return MediaType{}, err
+	}
+	return parsed.MediaType()
+}
+
+// Converts the mime-type to a MediaType, discarding any 'q'
+// parameter. Returns an error if the type or subtype is a wildcard.
+func (m Mime) MediaType() (MediaType, os.Error) {
+	if m.IsWildcard() {
+		return MediaType{}, os.NewError("Wildcards are not allowed in a media type")
+	}
+	return MediaType{m.WithoutParam("q")}, nil
+}
+
+// Converts the mime-type to a MediaRange, filling in a 'q' of 1
+// if none is present. Every MediaType is also a valid MediaRange.
+func (m Mime) MediaRange() MediaRange {
+	r := m.Clone()
+	if _, ok := r.params.get("q"); !ok {
+		r.setParam("q", "1")
+	}
+	return MediaRange{r}
+}
+
+// Returns the major type, e.g. 'text' for 'text/html'.
+func (t MediaType) Type() string {
+	return t.mime.mtype
+}
+
+// Returns the subtype, e.g. 'html' for 'text/html'.
+func (t MediaType) Subtype() string {
+	return t.mime.subtype
+}
+
+// Returns a copy of the parameters of the media type.
+func (t MediaType) Params() map[string]string {
+	return t.mime.Params()
+}
+
+// Serializes the media type, see Mime.String().
+func (t MediaType) String() string {
+	return t.mime.String()
+}
+
+// Returns a copy of the media type as a Mime, which may be changed
+// without affecting the MediaType.
+func (t MediaType) Mime() Mime {
+	return t.mime.Clone()
+}
+
+// Converts the media type to a MediaRange with a 'q' of 1.
+func (t MediaType) MediaRange() MediaRange {
+	return t.mime.MediaRange()
+}
+
+// Returns the major type, e.g. 'text' for 'text/*'.
+func (r MediaRange) Type() string {
+	return r.mime.mtype
+}
+
+// Returns the subtype, e.g. '*' for 'text/*'.
+func (r MediaRange) Subtype() string {
+	return r.mime.subtype
+}
+
+// Returns a copy of the ordinary parameters of the range, see
+// Mime.Params().
+func (r MediaRange) Params() map[string]string {
+	return r.mime.Params()
+}
+
+// Returns the 'q' quality parameter as a number.
+func (r MediaRange) Q() float64 {
+	return r.mime.Q()
+}
+
+// Reports whether the range matches more than one mime-type.
+func (r MediaRange) IsWildcard() bool {
+	return r.mime.IsWildcard()
+}
+
+// Serializes the range, 'q' included, see Mime.String().
+func (r MediaRange) String() string {
+	return r.mime.String()
+}
+
+// Returns a copy of the range as a Mime, which may be changed
+// without affecting the MediaRange.
+func (r MediaRange) Mime() Mime {
+	return r.mime.Clone()
+}
+
+// Converts the range to a MediaType, discarding its 'q' parameter.
+// Returns an error if the range is a wildcard.
+func (r MediaRange) MediaType() (MediaType, os.Error) {
+	return r.mime.MediaType()
+}
+
+// Returns the structured syntax suffix of the subtype as described
+// in RFC 6839, without the '+'. For example 'application/vnd.api+json'
+// has the suffix 'json'. Of a chain of suffixes, as in
+// 'application/vnd.foo+ber+der', this is the final one, 'der', which
+// names the outermost syntax. Returns "" if the subtype has no suffix.
+func (m Mime) Suffix() string {
+	if i := strings.LastIndex(m.subtype, "+"); i >= 0 {
+		return m.subtype[i+1:]
+	}
+	return ""
+}
+
+// Returns every structured syntax suffix of the subtype in the order
+// they appear, so 'application/vnd.foo+ber+der' gives 'ber' and 'der'.
+// Returns an empty list if the subtype has no suffix.
+func (m Mime) Suffixes() []string {
+	if i := strings.Index(m.subtype, "+"); i >= 0 {
+		return strings.Split(m.subtype[i+1:], "+", -1)
+	}
+	return []string{}
+}
+
+// Returns the subtype without its structured syntax suffixes. For
+// example 'application/vnd.api+json' has the base subtype 'vnd.api',
+// as does 'application/vnd.api+ber+der'.
+func (m Mime) BaseSubtype() string {
+	if i := strings.Index(m.subtype, "+"); i >= 0 {
+		return m.subtype[:i]
+	}
+	return m.subtype
+}
+
+// The registration tree of a subtype, see RFC 6838 section 3.
+type Tree int
+
+const (
+	StandardTree     Tree = iota // no facet, e.g. 'text/html'
+	VendorTree                   // 'vnd.' facet
+	PersonalTree                 // 'prs.' facet
+	UnregisteredTree             // 'x.' facet, or the legacy 'x-' prefix
+)
+
+var treeNames = []string{"standard", "vendor", "personal", "unregistered"}
+
+func (t Tree) String() string {
+	if t < 0 || int(t) >= len(treeNames) {
+		return "unknown"
+	}
+	return treeNames[t]
+}
+
+// Returns the facet of the subtype without its trailing separator,
+// i.e. 'vnd', 'prs' or 'x', or "" for the standards tree.
+func (m Mime) Facet() string {
+	switch {
+	case strings.HasPrefix(m.subtype, "vnd."):
+		return "vnd"
+	case strings.HasPrefix(m.subtype, "prs."):
+		return "prs"
+	case strings.HasPrefix(m.subtype, "x."), strings.HasPrefix(m.subtype, "x-"):
+		return "x"
+	}
+	return ""
+}
+
+// Returns the registration tree the subtype belongs to, so that
+// vendor and experimental types can be handled differently.
+func (m Mime) Tree() Tree {
+	switch m.Facet() {
+	case "vnd":
+		return VendorTree
+	case "prs":
+		return PersonalTree
+	case "x":
+		return UnregisteredTree
+	}
+	return StandardTree
+}
+
+// Message types whose body is a complete message, headers included,
+// see RFC 2046 section 5.2 and RFC 6532.
+var encapsulatingTypes = map[string]bool{
+	"message/rfc822": true,
+	"message/global": true,
+	"message/news":   true,
+}
+
+// Reports whether the type is a 'multipart' type, whose body is a
+// series of parts separated by a boundary, see Mime.Boundary().
+func (m Mime) IsMultipart() bool {
+	return m.mtype == "multipart"
+}
+
+// Reports whether the type is a 'message' type, such as
+// 'message/rfc822' or 'message/delivery-status'.
+func (m Mime) IsMessage() bool {
+	return m.mtype == "message"
+}
+
+// Reports whether the body of the type is itself a MIME entity or a
+// series of them, so that a mail scanner or archiver should parse it
+// further: every 'multipart' type, signed and encrypted ones
+// included, and the message types that encapsulate a whole message,
+// such as 'message/rfc822' and 'message/global'. A report such as
+// 'message/delivery-status' holds header fields but no entity, and
+// 'message/partial' only a fragment of one.
+func (m Mime) ContainsEntities() bool {
+	return m.IsMultipart() || encapsulatingTypes[m.mtype+"/"+m.subtype]
+}
+
+// Types outside the 'text' tree whose bodies are text.
+var textualTypes = map[string]bool{
+	"application/json":                  true,
+	"application/xml":                   true,
+	"application/javascript":            true,
+	"application/ecmascript":            true,
+	"application/x-www-form-urlencoded": true,
+	"application/yaml":                  true,
+	"application/x-yaml":                true,
+	"application/toml":                  true,
+	"application/sql":                   true,
+	"application/graphql":               true,
+	"application/x-sh":                  true,
+	"application/rtf":                   true,
+	"application/x-tex":                 true,
+	"message/rfc822":                    true,
+}
+
+// Structured syntax suffixes of textual syntaxes.
+var textualSuffixes = map[string]bool{"json": true, "xml": true, "yaml": true, "json-seq": true}
+
+// Reports whether the body of the type is text, so that it may carry
+// a charset and can be shown, logged or diffed line by line: every
+// 'text' type, types with a textual structured syntax suffix such as
+// 'image/svg+xml' or 'application/vnd.api+json', and some well known
+// types like 'application/json' and 'application/javascript'.
+func (m Mime) IsText() bool {
+	return m.mtype == "text" || textualSuffixes[m.Suffix()] || textualTypes[m.mtype+"/"+m.subtype]
+}
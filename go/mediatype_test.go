@@ -0,0 +1,199 @@
+package mimeparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMediaType(t *testing.T) {
+	mt, err := ParseMediaType("Text/HTML; charset=utf-8; q=0.5")
+	if err != nil {
+		t.Fatalf("Failed to parse media type: %v", err)
+	}
+	if mt.Type() != "text" || mt.Subtype() != "html" {
+		t.Errorf("Failed to parse type, got %s/%s", mt.Type(), mt.Subtype())
+	}
+	if !reflect.DeepEqual(mt.Params(), map[string]string{"charset": "utf-8"}) {
+		t.Errorf("Failed to discard q, got %v", mt.Params())
+	}
+	for _, bad := range []string{"text/*", "*/*", "*", "*/html", "html"} {
+		if _, err := ParseMediaType(bad); err == nil {
+			t.Errorf("ParseMediaType(%s) should fail", bad)
+		}
+	}
+}
+
+func TestMediaRangeConversion(t *testing.T) {
+	mt, _ := ParseMediaType("application/json")
+	r := mt.MediaRange()
+	if r.Q() != 1.0 || r.mime.params.value("q") != "1" {
+		t.Errorf("MediaRange() failed to add q, got %v", r.mime.params.toMap())
+	}
+	if _, ok := mt.mime.params.get("q"); ok {
+		t.Errorf("MediaRange() modified the MediaType")
+	}
+	parsed, _ := ParseMediaRange("image/*;q=0.3")
+	wild := parsed.MediaRange()
+	if wild.Q() != 0.3 {
+		t.Errorf("MediaRange() changed q, got %f", wild.Q())
+	}
+	if _, err := wild.MediaType(); err == nil {
+		t.Errorf("MediaType() of a wildcard range should fail")
+	}
+	concrete, _ := ParseMediaRange("image/png;q=0.3")
+	back, err := concrete.MediaRange().MediaType()
+	if err != nil || back.Type() != "image" || back.Subtype() != "png" || back.mime.params.len() != 0 {
+		t.Errorf("Failed to convert a concrete range to a MediaType, got %v %v", back, err)
+	}
+	// Changing the Mime of a MediaType cannot add a 'q' or a wildcard
+	// to it, and converting it back checks both again.
+	m := back.Mime()
+	m.SetQ(0.5)
+	if back.String() != "image/png" {
+		t.Errorf("Changing Mime() changed the MediaType to %s", back)
+	}
+	if again, err := m.WithParam("a", "b").MediaType(); err != nil || again.String() != "image/png;a=b" {
+		t.Errorf("MediaType() kept a q, got %v %v", again, err)
+	}
+	m, _ = ParseMimeType("image/*")
+	if _, err := m.MediaType(); err == nil {
+		t.Errorf("MediaType() of a changed Mime should check for wildcards")
+	}
+}
+
+func TestConstants(t *testing.T) {
+	for _, c := range []string{ApplicationJSON, ApplicationXML, ApplicationJavaScript,
+		ApplicationOctetStream, ApplicationForm, ApplicationPDF, ApplicationAtomXML,
+		ApplicationXHTMLXML, ApplicationProblemJSON, MultipartFormData, MultipartMixed,
+		MultipartAlternative, MultipartRelated, MultipartSigned, MultipartEncrypted,
+		MessageRFC822, MessageGlobal,
+		TextHTML, TextHTMLUTF8, TextPlain, TextPlainUTF8, TextCSS, TextCSV, TextXML,
+		TextJavaScript, ImagePNG, ImageJPEG, ImageGIF, ImageWebP, ImageSVGXML} {
+		if _, err := ParseMediaType(c); err != nil {
+			t.Errorf("Constant %s is not a valid media type: %v", c, err)
+		}
+	}
+	if BestMatch([]string{ApplicationJSON, TextHTML}, "text/html") != TextHTML {
+		t.Errorf("Constants should be usable in supported lists")
+	}
+}
+
+func TestSuffix(t *testing.T) {
+	cond := map[string][2]string{
+		"application/vnd.api+json":    {"json", "vnd.api"},
+		"application/atom+xml":        {"xml", "atom"},
+		"image/svg+xml;charset=utf8":  {"xml", "svg"},
+		"application/json":            {"", "json"},
+		"application/+json":           {"json", ""},
+		"text/*":                      {"", "*"},
+		"application/vnd.foo+ber+der": {"der", "vnd.foo"},
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if m.Suffix() != want[0] {
+			t.Errorf("Suffix() of %s == %s, not %s", mime, m.Suffix(), want[0])
+		}
+		if m.BaseSubtype() != want[1] {
+			t.Errorf("BaseSubtype() of %s == %s, not %s", mime, m.BaseSubtype(), want[1])
+		}
+	}
+}
+
+func TestSuffixChains(t *testing.T) {
+	cond := map[string][]string{
+		"application/vnd.foo+ber+der": {"ber", "der"},
+		"application/vnd.api+json":    {"json"},
+		"application/json":            {},
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := m.Suffixes(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Suffixes() of %s == %v, not %v", mime, got, want)
+		}
+	}
+	supported := []string{"application/vnd.foo+ber+der"}
+	p := &Policy{SuffixMatch: true}
+	headers := map[string]string{
+		"application/der":     "application/vnd.foo+ber+der",
+		"application/ber+der": "application/vnd.foo+ber+der",
+		"application/ber":     "",
+	}
+	for header, want := range headers {
+		if got := p.BestMatch(supported, header); got != want {
+			t.Errorf("BestMatch(%s) == %s, not %s", header, got, want)
+		}
+	}
+	for header, want := range map[string]string{"application/*+der": supported[0], "application/*+ber+der": supported[0], "application/*+ber": ""} {
+		if got := BestMatch(supported, header); got != want {
+			t.Errorf("BestMatch(%s) == %s, not %s", header, got, want)
+		}
+	}
+}
+
+func TestTree(t *testing.T) {
+	cond := map[string]Tree{
+		"application/vnd.api+json":          VendorTree,
+		"application/VND.ms-excel":          VendorTree,
+		"image/prs.btif":                    PersonalTree,
+		"application/x.foo":                 UnregisteredTree,
+		"application/x-www-form-urlencoded": UnregisteredTree,
+		"application/json":                  StandardTree,
+		"text/vndish":                       StandardTree,
+	}
+	facets := map[Tree]string{StandardTree: "", VendorTree: "vnd", PersonalTree: "prs", UnregisteredTree: "x"}
+	for mime, tree := range cond {
+		m, _ := ParseMimeType(mime)
+		if m.Tree() != tree {
+			t.Errorf("Tree() of %s == %s, not %s", mime, m.Tree(), tree)
+		}
+		if m.Facet() != facets[tree] {
+			t.Errorf("Facet() of %s == %s, not %s", mime, m.Facet(), facets[tree])
+		}
+	}
+	if VendorTree.String() != "vendor" || Tree(42).String() != "unknown" {
+		t.Errorf("Tree.String() failed")
+	}
+}
+
+func TestContainsEntities(t *testing.T) {
+	cond := map[string][3]bool{
+		"multipart/mixed; boundary=x":                    {true, false, true},
+		"multipart/signed; protocol=\"application/pgp\"": {true, false, true},
+		"Message/RFC822":                                 {false, true, true},
+		"message/global":                                 {false, true, true},
+		"message/delivery-status":                        {false, true, false},
+		"message/partial; id=x; number=1":                {false, true, false},
+		"text/plain":                                     {false, false, false},
+		"application/pkcs7-mime":                         {false, false, false},
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := [3]bool{m.IsMultipart(), m.IsMessage(), m.ContainsEntities()}; got != want {
+			t.Errorf("IsMultipart(), IsMessage(), ContainsEntities() of %s == %v, not %v", mime, got, want)
+		}
+	}
+}
+
+func TestIsText(t *testing.T) {
+	cond := map[string]bool{
+		"text/plain":                        true,
+		"Text/CSV; charset=utf-8":           true,
+		"application/json":                  true,
+		"application/vnd.api+json":          true,
+		"image/svg+xml":                     true,
+		"application/javascript":            true,
+		"application/x-www-form-urlencoded": true,
+		"application/vnd.foo+ber+der":       false,
+		"image/png":                         false,
+		"application/octet-stream":          false,
+		"application/pdf":                   false,
+		"multipart/form-data":               false,
+		"application/vnd.foo+zip":           false,
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := m.IsText(); got != want {
+			t.Errorf("IsText() of %s == %v, not %v", mime, got, want)
+		}
+	}
+}
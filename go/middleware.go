@@ -0,0 +1,132 @@
+package mimeparse
+
+import (
+	"http"
+	"sync"
+)
+
+// The writer Handler() passes on to the handler it wraps, which
+// carries the type negotiated for the request.
+type negotiatedWriter struct {
+	http.ResponseWriter
+	mime string
+}
+
+// Wraps a handler with content negotiation against the supported
+// types. Requests for which none of them is acceptable get a 406 Not
+// Acceptable response from NotAcceptable() and never reach 'h'. For
+// the others 'Accept' is added to the Vary header, the Content-Type
+// is set to the chosen type unless it is a wildcard, and the chosen
+// type is available to 'h' from NegotiatedType() of the writer it
+// is given.
+func Handler(supported []string, h http.Handler) http.Handler {
+	return defaultPolicy.Handler(supported, h)
+}
+
+// Like Handler() but applying the options of the policy.
+func (p *Policy) Handler(supported []string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		best, ok := p.BestMatchRange(supported, requestAccept(req.Header))
+		if !ok {
+			NotAcceptable(w, req, supported)
+			return
+		}
+		contentType := ""
+		if parsed, err := ParseMimeType(best.Type); err == nil && parsed.IsConcrete() {
+			contentType = best.Type
+		}
+		serveNegotiated(w, req, best.Type, contentType, h)
+	})
+}
+
+// Serves a request with 'h' once 'mime' has been chosen for it, as
+// described for Handler(), with 'contentType' unless it is "".
+func serveNegotiated(w http.ResponseWriter, req *http.Request, mime, contentType string, h http.Handler) {
+	addVary(w.Header(), "Accept")
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	h.ServeHTTP(&negotiatedWriter{w, mime}, req)
+}
+
+// Returns the type that Handler() negotiated for the response being
+// written to 'w', or "" if 'w' did not come from Handler(). A handler
+// nested in another sees the type it negotiated itself. The writer
+// may be wrapped by a NegotiatingWriter.
+func NegotiatedType(w http.ResponseWriter) string {
+	for nw, ok := w.(*NegotiatingWriter); ok; nw, ok = w.(*NegotiatingWriter) {
+		w = nw.ResponseWriter
+	}
+	if nw, ok := w.(*negotiatedWriter); ok {
+		return nw.mime
+	}
+	return ""
+}
+
+// Routes requests to handlers by the type of response they accept.
+// Each handler is registered for a type, which may be a wildcard such
+// as 'image/*' or a suffix wildcard such as 'application/*+json', and
+// requests are served as described for Handler(), except that the
+// Content-Type for a wildcard type is the concrete type of the
+// media-range that selected it, e.g. 'image/png' for 'image/*' chosen
+// by an Accept header of 'image/png', and is not set if that range is
+// itself a wildcard. The zero value is ready to use and matches with
+// the default policy.
+type Dispatcher struct {
+	// The options used for matching, nil for the default policy.
+	Policy   *Policy
+	mutex    sync.Mutex
+	types    []string
+	handlers []http.Handler
+}
+
+// Registers the handler for a type. Among types the client accepts
+// equally, the one registered first wins.
+func (d *Dispatcher) Register(mimetype string, h http.Handler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.types = append(d.types, mimetype)
+	d.handlers = append(d.handlers, h)
+}
+
+// Registers a handler function for a type, see Register().
+func (d *Dispatcher) RegisterFunc(mimetype string, f func(http.ResponseWriter, *http.Request)) {
+	d.Register(mimetype, http.HandlerFunc(f))
+}
+
+// Negotiates the type of the response to a request and serves it
+// with the handler registered for that type, or responds with 406
+// Not Acceptable.
+func (d *Dispatcher) Dispatch(w http.ResponseWriter, req *http.Request) {
+	d.mutex.Lock()
+	types, handlers := d.types, d.handlers
+	d.mutex.Unlock()
+	p := d.Policy
+	if p == nil {
+		p = defaultPolicy
+	}
+	best, ok := p.BestMatchRange(types, requestAccept(req.Header))
+	if !ok {
+		NotAcceptable(w, req, types)
+		return
+	}
+	serveNegotiated(w, req, best.Type, concreteType(best), handlers[best.index])
+}
+
+// Returns the chosen type of a match if it is concrete, or else the
+// media-range that selected it without its 'q', or "" if that is a
+// wildcard too.
+func concreteType(m Match) string {
+	if parsed, err := ParseMimeType(m.Type); err == nil && parsed.IsConcrete() {
+		return m.Type
+	}
+	if m.Range.IsConcrete() {
+		return formatMime(m.Range, m.Range.ParamKeys())
+	}
+	return ""
+}
+
+// Makes a Dispatcher an http.Handler, see Dispatch().
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.Dispatch(w, req)
+}
@@ -0,0 +1,131 @@
+package mimeparse
+
+import (
+	"http"
+	"http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var seen, contentType string
+	h := Handler([]string{"application/json", "text/*"}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen, contentType = NegotiatedType(w), w.Header().Get("Content-Type")
+		w.Write([]byte("ok"))
+	}))
+	cond := []struct {
+		accept, mime, contentType string
+		code                      int
+	}{
+		{"", "application/json", "application/json", http.StatusOK},
+		{"text/csv", "text/*", "", http.StatusOK},
+		{"image/png", "", ApplicationProblemJSON, http.StatusNotAcceptable},
+	}
+	for _, c := range cond {
+		seen, contentType = "", ""
+		req := &http.Request{Header: http.Header{}}
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if c.code == http.StatusNotAcceptable {
+			contentType = w.Header().Get("Content-Type")
+		}
+		if w.Code != c.code || seen != c.mime || contentType != c.contentType {
+			t.Errorf("Handler(%s) == %d, %s, %v", c.accept, w.Code, seen, w.Header())
+		}
+		if vary := w.Header()["Vary"]; len(vary) != 1 || vary[0] != "Accept" {
+			t.Errorf("Handler(%s) Vary == %v", c.accept, vary)
+		}
+		if NegotiatedType(w) != "" {
+			t.Errorf("NegotiatedType() of the writer passed to Handler(%s) == %s", c.accept, NegotiatedType(w))
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	d := &Dispatcher{}
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(name + " " + NegotiatedType(w)))
+		}
+	}
+	d.Register("application/vnd.example+json", handler("vendor"))
+	d.RegisterFunc("application/*+json", handler("json"))
+	d.RegisterFunc("text/html", handler("html"))
+	d.RegisterFunc("image/*", handler("image"))
+	cond := []struct {
+		accept, body, contentType string
+		code                      int
+	}{
+		{"text/html", "html text/html", "text/html", http.StatusOK},
+		{"application/vnd.example+json", "vendor application/vnd.example+json", "application/vnd.example+json", http.StatusOK},
+		{"application/ld+json", "json application/*+json", "application/ld+json", http.StatusOK},
+		{"image/png, text/html;q=0.5", "image image/*", "image/png", http.StatusOK},
+		{"", "vendor application/vnd.example+json", "application/vnd.example+json", http.StatusOK},
+		{"audio/ogg", "", ApplicationProblemJSON, http.StatusNotAcceptable},
+	}
+	for _, c := range cond {
+		req := &http.Request{Header: http.Header{}}
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, req)
+		if w.Code != c.code || w.Header().Get("Content-Type") != c.contentType {
+			t.Errorf("Dispatch(%s) == %d, %v", c.accept, w.Code, w.Header())
+		}
+		if c.code == http.StatusOK && w.Body.String() != c.body {
+			t.Errorf("Dispatch(%s) body == %s, not %s", c.accept, w.Body.String(), c.body)
+		}
+	}
+}
+
+func TestNestedHandlers(t *testing.T) {
+	var inner, outer, rendered string
+	d := &Dispatcher{}
+	d.RegisterFunc("text/html", func(w http.ResponseWriter, req *http.Request) {
+		inner = NegotiatedType(w)
+	})
+	d.Register("application/json", Negotiating(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rendered = NegotiatedType(w)
+	})))
+	h := Handler([]string{"text/*", "application/*"}, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		d.ServeHTTP(w, req)
+		outer = NegotiatedType(w)
+	}))
+	req := &http.Request{Header: http.Header{"Accept": {"text/html"}}}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if inner != "text/html" || outer != "text/*" {
+		t.Errorf("Nested handlers negotiated %s inside and %s outside", inner, outer)
+	}
+	req.Header.Set("Accept", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if rendered != "application/json" || outer != "application/*" {
+		t.Errorf("Nested handlers negotiated %s inside a NegotiatingWriter and %s outside", rendered, outer)
+	}
+}
+
+func TestObserverCountsRequestsOnce(t *testing.T) {
+	count := 0
+	p := &Policy{Observer: ObserverFunc(func(m Match, ok bool) {
+		count++
+	})}
+	supported := []string{"application/json", "image/*"}
+	noop := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	d := &Dispatcher{Policy: p}
+	d.Register("image/*", noop)
+	req := &http.Request{Header: http.Header{"Accept": {"image/png"}}}
+	p.Handler(supported, noop).ServeHTTP(httptest.NewRecorder(), req)
+	d.ServeHTTP(httptest.NewRecorder(), req)
+	m, _ := p.NewMatcher(supported)
+	m.Match("image/png")
+	if count != 3 {
+		t.Errorf("Observer called %d times for 3 negotiations", count)
+	}
+	p.Explain(supported, "image/png")
+	p.AllMatches(supported, "image/png")
+	if count != 3 {
+		t.Errorf("Observer called for Explain() or AllMatches()")
+	}
+}
@@ -15,7 +15,9 @@
 package mimeparse
 
 import (
+	"math"
 	"os"
+	"sort"
 	"strings"
 	"strconv"
 )
@@ -34,8 +36,179 @@ type Mime struct {
 	mtype string
 	// subtype
 	subtype string
-	// parameters
-	params map[string]string
+	// parameters, in the order they first appeared
+	params paramList
+	// names of the accept-ext parameters, those following 'q'
+	ext map[string]bool
+	// whether params is shared and must be copied before it is
+	// modified, see setParam()
+	shared bool
+}
+
+// The parameters of every media-range parsed without any, and of
+// the value returned along with a parse error.
+var (
+	defaultQParams = paramList{pairs: []param{{"q", "1"}}}
+	invalidQParams = paramList{pairs: []param{{"q", "0"}}}
+)
+
+// Returns the major type, e.g. 'text' for 'text/html'.
+func (m Mime) Type() string {
+	return m.mtype
+}
+
+// Returns the subtype, e.g. 'html' for 'text/html'.
+func (m Mime) Subtype() string {
+	return m.subtype
+}
+
+// Returns true for ranges that match more than one mime-type,
+// that is '*/*', 'type/*' or a suffix wildcard like 'type/*+json'.
+func (m Mime) IsWildcard() bool {
+	return m.mtype == "*" || m.subtype == "*" || strings.HasPrefix(m.subtype, "*+")
+}
+
+// Returns true if both the type and the subtype are given, so
+// the mime-type could be used as a Content-Type.
+func (m Mime) IsConcrete() bool {
+	return m.mtype != "" && m.subtype != "" && !m.IsWildcard()
+}
+
+// Returns true if the parameter 'key' is present.
+func (m Mime) HasParam(key string) bool {
+	_, ok := m.params.get(strings.ToLower(key))
+	return ok
+}
+
+// Returns true if there are any ordinary parameters, i.e.
+// parameters other than 'q' and accept-ext.
+func (m Mime) HasParams() bool {
+	for _, p := range m.params.pairs {
+		if m.isOrdinary(p.key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a deep copy of the mime-type. The parameters of the copy
+// may be modified without affecting the original, which matters
+// when parsed values are cached and shared between callers.
+func (m Mime) Clone() Mime {
+	var ext map[string]bool
+	if m.ext != nil {
+		ext = make(map[string]bool, len(m.ext))
+		for k := range m.ext {
+			ext[k] = true
+		}
+	}
+	return Mime{m.mtype, m.subtype, m.params.clone(), ext, false}
+}
+
+// Sets a parameter, remembering where it first appeared so
+// that the original parameter order can be reproduced.
+func (m *Mime) setParam(key, value string) {
+	if m.shared {
+		*m = m.Clone()
+	}
+	m.params.set(key, value)
+}
+
+// Returns a copy of the mime-type with the parameter 'key' set
+// to 'value', leaving the original untouched. For example:
+//
+// m.WithoutParam("q").WithParam("charset", "utf-8")
+func (m Mime) WithParam(key, value string) Mime {
+	c := m.Clone()
+	c.setParam(strings.ToLower(key), value)
+	return c
+}
+
+// Returns a copy of the mime-type with the parameter 'key' removed,
+// leaving the original untouched.
+func (m Mime) WithoutParam(key string) Mime {
+	key = strings.ToLower(key)
+	c := Mime{m.mtype, m.subtype, paramList{}, nil, false}
+	for _, p := range m.params.pairs {
+		if p.key != key {
+			c.setParam(p.key, p.value)
+			if m.ext[p.key] {
+				c.setExt(p.key)
+			}
+		}
+	}
+	return c
+}
+
+// Returns the 'q' quality parameter as a number. A missing or
+// malformed 'q' is treated as 1, the default for a media range.
+func (m Mime) Q() float64 {
+	if q, ok := m.params.get("q"); ok {
+		if val, err := strconv.Atof64(q); err == nil && val >= 0.0 && val <= 1.0 {
+			return val
+		}
+	}
+	return 1.0
+}
+
+// Sets the 'q' quality parameter, clamping the value to [0, 1].
+func (m *Mime) SetQ(q float64) {
+	if q < 0.0 {
+		q = 0.0
+	} else if q > 1.0 {
+		q = 1.0
+	}
+	m.setParam("q", strconv.Ftoa64(q, 'f', -1))
+}
+
+// Marks a parameter as an accept-ext parameter.
+func (m *Mime) setExt(key string) {
+	if m.ext == nil {
+		m.ext = make(map[string]bool)
+	}
+	m.ext[key] = true
+}
+
+// Reports whether 'key' names an ordinary parameter, i.e.
+// neither 'q' nor an accept-ext parameter.
+func (m Mime) isOrdinary(key string) bool {
+	return key != "q" && !m.ext[key]
+}
+
+// Returns a copy of the ordinary parameters of the mime-type, that
+// is every parameter except the 'q' quality parameter and the
+// accept-ext parameters that follow it.
+func (m Mime) Params() map[string]string {
+	params := make(map[string]string, len(m.params.pairs))
+	for _, p := range m.params.pairs {
+		if m.isOrdinary(p.key) {
+			params[p.key] = p.value
+		}
+	}
+	return params
+}
+
+// Returns a copy of the accept-ext parameters, those that follow
+// the 'q' parameter of a media-range. As described in RFC 7231
+// they are not part of the media-range and take no part in matching.
+func (m Mime) AcceptExt() map[string]string {
+	ext := make(map[string]string, len(m.ext))
+	for k := range m.ext {
+		ext[k] = m.params.value(k)
+	}
+	return ext
+}
+
+// Returns the names of the ordinary parameters, everything
+// except 'q' and accept-ext, in the order they appeared in the source.
+func (m Mime) ParamKeys() []string {
+	keys := make([]string, 0, len(m.params.pairs))
+	for _, p := range m.params.pairs {
+		if m.isOrdinary(p.key) {
+			keys = append(keys, p.key)
+		}
+	}
+	return keys
 }
 
 // Carves up a mime-type and returns a struct of the
@@ -45,27 +218,130 @@ type Mime struct {
 // get parsed into:
 //
 // Mime {'application', 'xhtml', {'q', '0.5'}}, nil
+//
+// Parameter values may be quoted-strings, in which case the
+// quotes and backslash escapes are removed from the value. A
+// repeated parameter keeps its last value, except for 'q': the first
+// 'q' ends the media-range and gives its weight, while a later one
+// such as in 'text/html;q=0.5;q=0.9' is an accept-ext and dropped.
+// The type, subtype and parameter names are cut short at the first
+// character not allowed in a token, so 'text/html<script>' parses as
+// 'text/html', and empty parameters as in 'text/html;' are skipped.
+// Any control character other than tab, such as a CR or LF, is
+// refused with ErrControlChar, and a mime-type exceeding the limits
+// of Policy.MaxLength or Policy.MaxParams with ErrTooLarge.
 func ParseMimeType(mimetype string) (parsed Mime, err os.Error) {
-	full_type, parts := ht(strings.Split(mimetype, ";", -1))
-	full_type = strings.ToLower(full_type)
-	params := make(map[string]string)
-	for _, s := range parts {
-		subparts := strings.Split(s, "=", 2)
-		if len(subparts) == 2 {
-			params[strings.ToLower(strings.TrimSpace(subparts[0]))] = strings.TrimSpace(subparts[1])
-		} else {
-			params[strings.ToLower(strings.TrimSpace(subparts[0]))] = ""
+	return defaultPolicy.ParseMimeType(mimetype)
+}
+
+// Like ParseMimeType() but applying the options of the policy.
+// With Strict set a repeated parameter other than 'q' is an error, otherwise
+// Policy.RepeatedParams decides which value is kept. With Strict set a
+// character not allowed in a token is an error as well.
+func (p *Policy) ParseMimeType(mimetype string) (parsed Mime, err os.Error) {
+	return p.parseMimeType(mimetype, false)
+}
+
+// Parses a mime-type as ParseMimeType() does. The type and subtype
+// must be tokens unless 'pattern' is set, see CompilePattern(). With
+// Strict set other characters are an error, otherwise the type and
+// subtype are cut short at the first of them, as are parameter names.
+func (p *Policy) parseMimeType(mimetype string, pattern bool) (parsed Mime, err os.Error) {
+	if p.MailSyntax {
+		mimetype = unfold(mimetype)
+	}
+	if err = checkControl(mimetype); err != nil {
+		return invalidMime(), err
+	}
+	if p.MailSyntax {
+		mimetype = stripComments(mimetype)
+	}
+	if exceeds(len(mimetype), p.MaxLength, DefaultMaxLength) {
+		return invalidMime(), ErrTooLarge
+	}
+	// The parameters are scanned in place into a list made only once
+	// one is found, with room for a 'q' to be added, so a mime-type is
+	// parsed with at most one allocation.
+	end := nextQuoted(mimetype, 0, ';')
+	n := countQuoted(mimetype, ';') - 1
+	if exceeds(n, p.MaxParams, DefaultMaxParams) {
+		return invalidMime(), ErrTooLarge
+	}
+	if n > 0 && n <= maxInlineParams {
+		parsed.params.pairs = make([]param, 0, n+1)
+	}
+	full_type := lowerASCII(mimetype[:end])
+	seenQ := false
+	for i := end; i < len(mimetype); {
+		next := nextQuoted(mimetype, i+1, ';')
+		s := mimetype[i+1 : next]
+		i = next
+		key, value, hasValue := s, "", false
+		if eq := strings.Index(s, "="); eq >= 0 {
+			key, value, hasValue = s[:eq], s[eq+1:], true
+		}
+		key = intern(lowerASCII(strings.TrimSpace(key)))
+		if !isToken(key) {
+			if p.Strict && key != "" {
+				return invalidMime(), os.NewError("Invalid parameter name")
+			}
+			// An empty parameter, as in 'text/html;', is allowed.
+			if key = tokenPrefix(key); key == "" {
+				continue
+			}
 		}
+		if _, repeated := parsed.params.get(key); repeated {
+			// Only the first 'q' is the weight. Any later 'q', like an
+			// accept-ext named as an earlier parameter, cannot be kept
+			// alongside it and would take no part in matching anyway,
+			// so it must not replace or reclassify that parameter.
+			if seenQ {
+				continue
+			}
+			if p.Strict {
+				return invalidMime(), os.NewError("Repeated parameter")
+			}
+			if p.RepeatedParams == FirstParamWins {
+				continue
+			}
+		}
+		if hasValue {
+			value = unquote(strings.TrimSpace(value))
+			if p.EncodedWords {
+				value = DecodeEncodedWords(value)
+			}
+		}
+		parsed.setParam(key, value)
+		if seenQ && key != "q" {
+			parsed.setExt(key)
+		}
+		seenQ = seenQ || key == "q"
 	}
 	if strings.TrimSpace(full_type) == "*" {
 		full_type = "*/*"
 	}
-	list := strings.Split(full_type, "/", -1)
-	if len(list) != 2 {
-		return Mime{"", "", map[string]string{"q": "0"}}, os.NewError("Not a valid mimetype")
+	slash := strings.Index(full_type, "/")
+	if slash < 0 || strings.Index(full_type[slash+1:], "/") >= 0 {
+		return invalidMime(), os.NewError("Not a valid mimetype")
 	}
-	maintype, subtype := list[0], list[1]
-	return Mime{strings.TrimSpace(maintype), strings.TrimSpace(subtype), params}, nil
+	maintype, subtype := strings.TrimSpace(full_type[:slash]), strings.TrimSpace(full_type[slash+1:])
+	if !pattern && (!isToken(maintype) || !isToken(subtype)) {
+		if p.Strict {
+			return invalidMime(), os.NewError("Invalid character in mimetype")
+		}
+		maintype, subtype = tokenPrefix(maintype), tokenPrefix(subtype)
+		if maintype == "" || subtype == "" {
+			return invalidMime(), os.NewError("Not a valid mimetype")
+		}
+	}
+	parsed.mtype, parsed.subtype = intern(maintype), intern(subtype)
+	return parsed, nil
+}
+
+// The value returned along with a parse error. Its 'q' of 0
+// ensures it never contributes to a match.
+func invalidMime() Mime {
+	return Mime{"", "", invalidQParams, nil, true}
 }
 
 // Carves up a media range and returns a tuple of the
@@ -79,61 +355,206 @@ func ParseMimeType(mimetype string) (parsed Mime, err os.Error) {
 // In addition this function also guarantees that there
 // is a value for 'q' in the params dictionary, filling it
 // in with a proper default if necessary.
+//
+// A wildcard type with a concrete subtype, such as '*/xml', is
+// not allowed by RFC 7231 and is read as '*/*'.
 func ParseMediaRange(mediarange string) (mime Mime, err os.Error) {
-	parsed, err := ParseMimeType(mediarange)
+	return defaultPolicy.ParseMediaRange(mediarange)
+}
+
+// Like ParseMediaRange() but applying the options of the policy.
+// With Strict set a range such as '*/xml' is an error, as is a 'q'
+// with more than three decimals, which is otherwise rounded to three.
+// A malformed 'q' is handled as chosen by Policy.InvalidQ.
+func (p *Policy) ParseMediaRange(mediarange string) (mime Mime, err os.Error) {
+	parsed, err := p.ParseMimeType(mediarange)
 	if err != nil {
 		return parsed, err
 	}
-	if q, ok := parsed.params["q"]; ok {
-		if val, err := strconv.Atof(q); err != nil || val > 1.0 || val < 0.0 {
-			parsed.params["q"] = "1"
+	if parsed.mtype == "*" && parsed.subtype != "*" {
+		if p.Strict {
+			return invalidMime(), os.NewError("Wildcard type with a concrete subtype")
+		}
+		parsed.subtype = "*"
+	}
+	if q, ok := parsed.params.get("q"); ok {
+		if val, err := strconv.Atof64(q); err != nil || val > 1.0 || val < 0.0 {
+			switch {
+			case p.Strict || p.InvalidQ == InvalidQAsError:
+				return invalidMime(), os.NewError("Invalid quality value")
+			case p.InvalidQ == InvalidQAsZero:
+				parsed.setParam("q", "0")
+			default:
+				parsed.setParam("q", "1")
+			}
+		} else if !isQValue(q) {
+			if p.Strict {
+				return invalidMime(), os.NewError("Quality value not in the qvalue syntax")
+			}
+			if rounded := math.Floor(val*1000+0.5) / 1000; rounded != val {
+				parsed.setParam("q", strconv.Ftoa64(rounded, 'f', -1))
+			}
 		}
+	} else if parsed.params.len() == 0 {
+		// Ranges without parameters share the parameters holding
+		// the default 'q', which spares them any allocation.
+		parsed.params, parsed.shared = defaultQParams, true
 	} else {
-		parsed.params["q"] = "1"
+		parsed.setParam("q", "1")
 	}
 	return parsed, nil
 }
 
 
+// Computes how well the media-range 'r' fits the mime-type 'target',
+// or -1 if it does not match at all. Following RFC 7231 section 5.3.2
+// every ordinary parameter of the range must be present with the same
+// value in the target. A range naming the type beats one with a wildcard
+// type, likewise for the subtype, and among those each parameter of the
+// range makes it more specific. Parameter values are compared as
+// described for Policy.CaseInsensitiveParams and Policy.ListParams,
+// see also Policy.StrictParams. Policy.Scorer replaces all of this.
+func (p *Policy) rangeFitness(target, r Mime) int {
+	if p.Scorer != nil {
+		score, ok := p.Scorer(target, r)
+		switch {
+		case !ok:
+			return -1
+		case score < 0:
+			return 0
+		}
+		return score
+	}
+	if !(r.mtype == target.mtype || r.mtype == "*" || target.mtype == "*") ||
+		!(sameSubtype(target, r) || r.subtype == "*" || target.subtype == "*" || p.anySuffixMatch(target, r)) {
+		return -1
+	}
+	fitness := 1
+	if p.IgnoreParams {
+		return fitness + p.typeFitness(target, r)
+	}
+	// Walk the parameters in their parsed order.
+	for _, key := range r.ParamKeys() {
+		if targetvalue, ok := target.params.get(key); !ok || !p.paramMatch(key, r.params.value(key), targetvalue) {
+			return -1
+		}
+		fitness++
+	}
+	if p.StrictParams && !r.IsWildcard() {
+		for _, key := range target.ParamKeys() {
+			if _, ok := r.params.get(key); !ok {
+				return -1
+			}
+		}
+	}
+	return fitness + p.typeFitness(target, r)
+}
+
+// Reports whether the range names the structured syntax suffix of the
+// target, e.g. 'application/json' for 'application/vnd.api+json', and
+// the policy allows that to match. Of a chain of suffixes the range
+// must name the final one or a tail of the chain, so 'application/der'
+// and 'application/ber+der' match 'application/vnd.foo+ber+der' while
+// 'application/ber' does not.
+func (p *Policy) suffixMatch(target, r Mime) bool {
+	return p.SuffixMatch && r.mtype == target.mtype && r.subtype != "" && strings.HasSuffix(target.subtype, "+"+r.subtype)
+}
+
+// Reports whether the range matches the target through a structured
+// syntax suffix, either with SuffixMatch or with a suffix wildcard on
+// either side. Streaming types never match this way, since a client
+// asking for JSON documents cannot read a stream of them.
+func (p *Policy) anySuffixMatch(target, r Mime) bool {
+	if target.IsStreaming() || r.IsStreaming() {
+		return false
+	}
+	return p.suffixMatch(target, r) ||
+		suffixWildcardMatch(target.subtype, r.subtype) || suffixWildcardMatch(r.subtype, target.subtype)
+}
+
+// Reports whether 'pattern' is a suffix wildcard such as '*+json'
+// and 'subtype' has that suffix, e.g. 'vnd.api+json'. With a chain of
+// suffixes the pattern must name its final one or a tail of it, as
+// '*+der' and '*+ber+der' both do for 'vnd.foo+ber+der'.
+func suffixWildcardMatch(pattern, subtype string) bool {
+	return strings.HasPrefix(pattern, "*+") && strings.HasSuffix(subtype, pattern[1:])
+}
+
+// Reports whether two mime-types have the same subtype, taking the
+// gRPC types without a codec to be the same as those with '+proto'.
+func sameSubtype(a, b Mime) bool {
+	return a.subtype == b.subtype || (a.mtype == b.mtype && grpcSubtype(a) == grpcSubtype(b))
+}
+
+// The part of the fitness that comes from the type and subtype.
+func (p *Policy) typeFitness(target, r Mime) int {
+	fitness := 0
+	if sameSubtype(target, r) {
+		fitness += 10
+	} else if p.anySuffixMatch(target, r) {
+		fitness += 5
+	}
+	if r.mtype == target.mtype {
+		fitness += 100
+	}
+	return fitness
+}
+
 // Find the best match for a given mime-type against
 // a list of media_ranges that have already been
 // parsed by ParseMediaRange(). Returns a tuple of
 // the fitness value and the value of the 'q' quality
 // parameter of the best match, or (-1, 0) if no match
-// was found. Just as for QualityParsed(), 'parsedranges'
-// must be a list of parsed media ranges.
+// was found. The best match is the most specific range
+// that matches, as defined by RFC 7231. Just as for
+// QualityParsed(), 'parsedranges' must be a list of parsed
+// media ranges.
 func FitnessAndQuality(mimetype string, parsedRanges []Mime) (fitness int, quality float) {
-	bestfitness := -1
-	bestquality := 0.0
-	target, _ := ParseMediaRange(mimetype)
-	for _, r := range parsedRanges {
-		pmatches := 0
-		fitness := 0
-		if (r.mtype == target.mtype || r.mtype == "*" || target.mtype == "*") &&
-			(r.subtype == target.subtype || r.subtype == "*" || target.subtype == "*") {
-			fitness += 1
-			for key, targetvalue := range target.params {
-				if key != "q" {
-					if value, ok := r.params[key]; ok && value == targetvalue {
-						pmatches++
-					}
-				}
-			}
-			fitness += pmatches
-			if r.subtype == target.subtype {
-				fitness += 10
-			}
-			if r.mtype == target.mtype {
-				fitness += 100
-			}
-			if fitness > bestfitness {
-				bestfitness = fitness
-				bestquality, _ = strconv.Atof(r.params["q"])
-			}
-		}
+	return defaultPolicy.FitnessAndQuality(mimetype, parsedRanges)
+}
+
+// Like FitnessAndQuality() but applying the options of the policy.
+func (p *Policy) FitnessAndQuality(mimetype string, parsedRanges []Mime) (fitness int, quality float) {
+	_, index, fitness := p.bestRange(mimetype, parsedRanges)
+	if index < 0 {
+		return -1, 0.0
 	}
+	quality, _ = strconv.Atof(parsedRanges[index].params.value("q"))
+	return fitness, quality
+}
 
-	return bestfitness, bestquality
+// Returns the index in 'parsedRanges' of the range that best fits
+// the mime-type and its fitness, or (-1, -1) if none match, along
+// with the parsed (and normalized) mime-type. Among ranges of equal fitness the
+// first one wins.
+func (p *Policy) bestRange(mimetype string, parsedRanges []Mime) (target Mime, index, fitness int) {
+	target = p.parseTarget(mimetype)
+	index, fitness = p.bestTargetRange(target, parsedRanges)
+	return target, index, fitness
+}
+
+// Parses a supported type for matching, normalizing it if the
+// policy asks for that.
+func (p *Policy) parseTarget(mimetype string) Mime {
+	target, _ := p.ParseMediaRange(mimetype)
+	if p.NormalizeAliases {
+		target = Normalize(target)
+	}
+	return target
+}
+
+// Like bestRange() for a type already parsed by parseTarget().
+func (p *Policy) bestTargetRange(target Mime, parsedRanges []Mime) (index, fitness int) {
+	index, fitness = -1, -1
+	for i, r := range parsedRanges {
+		if p.NormalizeAliases {
+			r = Normalize(r)
+		}
+		if f := p.rangeFitness(target, r); f > fitness {
+			index, fitness = i, f
+		}
+	}
+	return index, fitness
 }
 
 //    Find the best match for a given mime-type against
@@ -144,50 +565,541 @@ func FitnessAndQuality(mimetype string, parsedRanges []Mime) (fitness int, quali
 //    except that 'parsed_ranges' must be a list of
 //    parsed media ranges.
 func QualityParsed(mimetype string, parsedRanges []Mime) (quality float) {
-	_, quality = FitnessAndQuality(mimetype, parsedRanges)
+	return defaultPolicy.QualityParsed(mimetype, parsedRanges)
+}
+
+// Like QualityParsed() but applying the options of the policy.
+func (p *Policy) QualityParsed(mimetype string, parsedRanges []Mime) (quality float) {
+	_, quality = p.FitnessAndQuality(mimetype, parsedRanges)
 	return
 }
 
+// Splits an Accept header into its media-ranges and parses each
+// one with ParseMediaRange(). Commas inside quoted-strings do not
+// separate ranges. A header with a control character other than tab
+// or exceeding the limits of Policy.MaxLength or Policy.MaxRanges is
+// refused as a whole and gives no ranges.
 func ParseHeader(header string) (parsed []Mime) {
-	ranges := strings.Split(header, ",", -1)
-	parsed = make([]Mime, len(ranges))
-	for i, r := range ranges {
-		parsed[i], _ = ParseMediaRange(r)
-	}
+	return defaultPolicy.ParseHeader(header)
+}
+
+// Like ParseHeader() but applying the options of the policy.
+func (p *Policy) ParseHeader(header string) (parsed []Mime) {
+	parsed, _ = p.parseHeader(header)
 	return
 }
 
+// Like ParseHeader() but also reports the malformed media-ranges of
+// the header, which are left out of the ranges returned so that a
+// lenient caller can go on with the others. The error is a
+// HeaderError listing each malformed range with its position, or
+// ErrControlChar or ErrTooLarge for a header refused as a whole.
+// Empty elements, as in 'text/html, , text/plain', are not errors.
+func ParseHeaderWithErrors(header string) ([]Mime, os.Error) {
+	return defaultPolicy.ParseHeaderWithErrors(header)
+}
+
+// Like ParseHeaderWithErrors() but applying the options of the policy.
+func (p *Policy) ParseHeaderWithErrors(header string) ([]Mime, os.Error) {
+	parsed, err := p.parseHeader(header)
+	valid := []Mime{}
+	for _, m := range parsed {
+		if m.mtype != "" {
+			valid = append(valid, m)
+		}
+	}
+	return valid, err
+}
+
+// A malformed media-range of a header, see ParseHeaderWithErrors().
+type RangeError struct {
+	Index int      // position of the range among the elements of the header
+	Range string   // the range as it appears in the header
+	Err   os.Error // why it could not be parsed
+}
+
+func (e *RangeError) String() string {
+	return "Media-range " + strconv.Itoa(e.Index) + " " + strconv.Quote(e.Range) + ": " + e.Err.String()
+}
+
+// The malformed media-ranges of a header, in order.
+type HeaderError []*RangeError
+
+func (e HeaderError) String() string {
+	s := make([]string, len(e))
+	for i, r := range e {
+		s[i] = r.String()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Parses a header as ParseHeader() does, returning the error that
+// made it refuse the header or else a HeaderError for its malformed
+// ranges, if any.
+func (p *Policy) parseHeader(header string) ([]Mime, os.Error) {
+	// The headers sent by most clients are parsed only once, and
+	// handed out as copies.
+	if p == defaultPolicy {
+		if common, ok := commonHeaders[header]; ok {
+			parsed := getRanges(len(common))
+			copy(parsed, common)
+			return parsed, nil
+		}
+	}
+	if err := checkControl(header); err != nil {
+		return []Mime{}, err
+	}
+	if exceeds(len(header), p.MaxLength, DefaultMaxLength) {
+		return []Mime{}, ErrTooLarge
+	}
+	n := countQuoted(header, ',')
+	if exceeds(n, p.MaxRanges, DefaultMaxRanges) {
+		return []Mime{}, ErrTooLarge
+	}
+	// The ranges are scanned in place, so that the result is the only
+	// allocation for a header of ranges without parameters, and none
+	// when a recycled list is available.
+	parsed := getRanges(n)
+	var errs HeaderError
+	for i, start := 0, 0; i < n; i++ {
+		end := nextQuoted(header, start, ',')
+		r := header[start:end]
+		start = end + 1
+		var err os.Error
+		if parsed[i], err = p.ParseMediaRange(r); err != nil && strings.TrimSpace(r) != "" {
+			errs = append(errs, &RangeError{i, strings.TrimSpace(r), err})
+		}
+	}
+	if p.MergeDuplicates {
+		parsed = NewMediaRangeSet(parsed...).Ranges()
+	}
+	if len(errs) > 0 {
+		return parsed, errs
+	}
+	return parsed, nil
+}
+
 // Returns the quality 'q' of a mime-type when compared
 // against the media-ranges in ranges. For example:
 //
 // Quality('text/html','text/*;q=0.3, text/html;q=0.7, text/html;level=1, text/html;level=2;q=0.4, * / *;q=0.5')
 // 0.7
 func Quality(mimetype string, ranges string) (quality float) {
-	return QualityParsed(mimetype, ParseHeader(ranges))
+	return defaultPolicy.Quality(mimetype, ranges)
+}
+
+// Like Quality() but applying the options of the policy.
+func (p *Policy) Quality(mimetype string, ranges string) (quality float) {
+	parsed := p.ParseHeader(ranges)
+	quality = p.QualityParsed(mimetype, parsed)
+	Recycle(parsed)
+	return
 }
 
 //  Takes a list of supported mime-types and finds the best
 //  match for all the media-ranges listed in header. The value of
 //  header must be a string that conforms to the format of the
 //  HTTP Accept: header. The value of 'supported' is a list of
-//  mime-types.
+//  mime-types. A media-range with a 'q' of 0 marks the types it
+//  matches as not acceptable, and those are never returned; ""
+//  is returned if no supported type is acceptable.
+//
+//  The order of 'supported' is the server's order of preference:
+//  when several types are acceptable with the same quality the one
+//  listed first wins, e.g. for a header of '*/*'.
+//  Set Policy.ClientOrder to prefer the order of the header instead.
+//
+//  Supported types may carry parameters of their own. A media-range
+//  with parameters only selects supported types that have the same
+//  values, so a header of 'application/vnd.api+json;version=1' picks
+//  that entry out of several versions of the type.
+//
+//  A supported type may use a suffix wildcard such as 'application/*+json'
+//  to accept any subtype with that structured syntax suffix, e.g. a
+//  header of 'application/vnd.api+json'.
 //
 //  BestMatch(['application/xbel+xml', 'text/xml'], 'text/*;q=0.5,* /*; q=0.1')
 //  'text/xml'
 func BestMatch(supported []string, header string) string {
-	parsedHeader := ParseHeader(header)
-	if len(supported) == 0 {
+	return defaultPolicy.BestMatch(supported, header)
+}
+
+// Like BestMatch() but applying the options of the policy.
+func (p *Policy) BestMatch(supported []string, header string) string {
+	parsed := p.ParseHeader(header)
+	best, _ := p.negotiate(supported, parsed)
+	Recycle(parsed)
+	return best.Type
+}
+
+// Like BestMatch() but also returns the quality and the fitness of
+// the winning type, as computed by FitnessAndQuality(), so callers can
+// log them or apply their own threshold. Returns ("", 0, -1) if no
+// supported type is acceptable.
+func BestMatchWithQuality(supported []string, header string) (mime string, quality float, fitness int) {
+	return defaultPolicy.BestMatchWithQuality(supported, header)
+}
+
+// Like BestMatchWithQuality() but applying the options of the policy.
+func (p *Policy) BestMatchWithQuality(supported []string, header string) (mime string, quality float, fitness int) {
+	parsed := p.ParseHeader(header)
+	best, _ := p.negotiate(supported, parsed)
+	Recycle(parsed)
+	return best.Type, best.Quality, best.Fitness
+}
+
+// Like BestMatch() but returns the index of the winning type in
+// 'supported', or -1 if none is acceptable. This suits callers that
+// keep data in a slice parallel to the supported types, and tells
+// apart duplicate entries.
+//
+// BestMatchIndex(['application/json', 'text/html'], 'text/*')
+// 1
+func BestMatchIndex(supported []string, header string) int {
+	return defaultPolicy.BestMatchIndex(supported, header)
+}
+
+// Like BestMatchIndex() but applying the options of the policy.
+func (p *Policy) BestMatchIndex(supported []string, header string) int {
+	parsed := p.ParseHeader(header)
+	best, _ := p.negotiate(supported, parsed)
+	Recycle(parsed)
+	return best.index
+}
+
+// Like BestMatch() but returns 'fallback' when no supported type
+// is acceptable, for servers that prefer sending a default type
+// over responding with 406 Not Acceptable.
+//
+// BestMatchWithDefault(['text/html'], 'image/png', 'application/json')
+// 'application/json'
+func BestMatchWithDefault(supported []string, header string, fallback string) string {
+	return defaultPolicy.BestMatchWithDefault(supported, header, fallback)
+}
+
+// Like BestMatchWithDefault() but applying the options of the policy.
+func (p *Policy) BestMatchWithDefault(supported []string, header string, fallback string) string {
+	if mime := p.BestMatch(supported, header); mime != "" {
+		return mime
+	}
+	return fallback
+}
+
+// Like BestMatch() but when the winning supported type is a wildcard,
+// such as 'image/*', returns the concrete type the client asked for
+// so the result can be sent as a Content-Type. It is taken, with its
+// parameters, from the most acceptable concrete media-range that the
+// wildcard covers. Returns "" if no supported type is acceptable or
+// the client named no concrete type for the wildcard.
+//
+// BestMatchConcrete(['image/*', 'text/html'], 'image/png')
+// 'image/png'
+func BestMatchConcrete(supported []string, header string) string {
+	return defaultPolicy.BestMatchConcrete(supported, header)
+}
+
+// Like BestMatchConcrete() but applying the options of the policy.
+func (p *Policy) BestMatchConcrete(supported []string, header string) string {
+	parsedHeader := p.ParseHeader(header)
+	defer Recycle(parsedHeader)
+	best, ok := p.negotiate(supported, parsedHeader)
+	if !ok {
 		return ""
 	}
-	bestquality := 0.0
-	bestmime := ""
-	for _, mime := range supported {
-		_, quality := FitnessAndQuality(mime, parsedHeader)
-		if quality > bestquality {
-			bestquality = quality
-			bestmime = mime
+	target, _ := p.ParseMediaRange(best.Type)
+	if !target.IsWildcard() {
+		return best.Type
+	}
+	var resolved Mime
+	var quality float64
+	for _, r := range parsedHeader {
+		if r.IsConcrete() && r.Q() > quality && p.rangeFitness(r, target) >= 0 {
+			resolved, quality = r, r.Q()
 		}
 	}
+	if quality == 0 {
+		return ""
+	}
+	return formatMime(resolved, resolved.ParamKeys())
+}
+
+// The outcome of negotiating a supported type against a header.
+type Match struct {
+	// The chosen entry of the supported types.
+	Type string
+	// The media-range of the header that selected it, giving
+	// access to parameters such as 'charset' or 'level'.
+	Range Mime
+	// The quality and fitness of Range, see FitnessAndQuality().
+	Quality float
+	Fitness int
+	// How closely Range describes Type, and how many parameters
+	// of Range had to match.
+	Specificity Specificity
+	Params      int
 
-	return bestmime
+	index      int // of Type in the supported types
+	rangeIndex int // of Range in the header
+}
+
+var noMatch = Match{Fitness: -1, index: -1, rangeIndex: -1}
+
+// Returns the index of Type in the supported types.
+func (m Match) Index() int {
+	return m.index
+}
+
+// Returns the index of Range among the media-ranges of the header.
+func (m Match) RangeIndex() int {
+	return m.rangeIndex
+}
+
+// How closely the media-range that selected a type describes it,
+// from least to most specific.
+type Specificity int
+
+const (
+	NoMatch      Specificity = iota // nothing matched
+	AnyMatch                        // the range was '*/*'
+	TypeMatch                       // the range was 'type/*'
+	PartialMatch                    // the range matched through a wildcard or suffix of the supported type
+	ExactMatch                      // the range named the supported type and subtype
+)
+
+var specificityNames = []string{"none", "any", "type", "partial", "exact"}
+
+func (s Specificity) String() string {
+	if s < 0 || int(s) >= len(specificityNames) {
+		return "invalid"
+	}
+	return specificityNames[s]
+}
+
+// Classifies a matching range against the mime-type it matched.
+func (p *Policy) specificity(target, r Mime) (s Specificity, params int) {
+	if !p.IgnoreParams {
+		params = len(r.ParamKeys())
+	}
+	switch {
+	case r.mtype == "*":
+		return AnyMatch, params
+	case r.subtype == "*":
+		return TypeMatch, params
+	case r.mtype == target.mtype && sameSubtype(target, r):
+		return ExactMatch, params
+	}
+	return PartialMatch, params
+}
+
+// Matches the supported type at index 'i' against the header.
+func (p *Policy) matchSupported(i int, mime string, parsedHeader []Mime) (Match, bool) {
+	return p.matchTarget(i, mime, p.parseTarget(mime), parsedHeader)
+}
+
+// Like matchSupported() for a type already parsed by parseTarget().
+func (p *Policy) matchTarget(i int, mime string, target Mime, parsedHeader []Mime) (Match, bool) {
+	index, fitness := p.bestTargetRange(target, parsedHeader)
+	if index < 0 {
+		return noMatch, false
+	}
+	r := parsedHeader[index]
+	if p.NormalizeAliases {
+		r = Normalize(r)
+	}
+	quality, _ := strconv.Atof(r.params.value("q"))
+	specificity, params := p.specificity(target, r)
+	return Match{mime, parsedHeader[index], quality, fitness, specificity, params, i, index}, true
+}
+
+// Like BestMatch() but returns the chosen type along with the
+// media-range that selected it. The boolean is false if no
+// supported type is acceptable.
+func BestMatchRange(supported []string, header string) (Match, bool) {
+	return defaultPolicy.BestMatchRange(supported, header)
+}
+
+// Like BestMatchRange() but applying the options of the policy.
+func (p *Policy) BestMatchRange(supported []string, header string) (Match, bool) {
+	parsed := p.ParseHeader(header)
+	best, ok := p.negotiate(supported, parsed)
+	Recycle(parsed)
+	return best, ok
+}
+
+// Returns every acceptable supported type, most preferred first,
+// for callers that want to fall back to another representation when
+// the first one fails. Types are ordered by quality, then by the
+// fitness of the media-range that accepted them, then by their order
+// in 'supported'. Because of the fitness step the first entry can
+// differ from the choice of BestMatch(), which only considers quality
+// and order.
+func AllMatches(supported []string, header string) []Match {
+	return defaultPolicy.AllMatches(supported, header)
+}
+
+// Like AllMatches() but applying the options of the policy. With
+// ClientOrder set, the position of the media-range in the header
+// is considered before its fitness.
+func (p *Policy) AllMatches(supported []string, header string) []Match {
+	parsedHeader := p.ParseHeader(header)
+	matches := []Match{}
+	for i, mime := range supported {
+		if m, ok := p.matchSupported(i, mime, parsedHeader); ok && m.Quality > 0 {
+			matches = append(matches, m)
+		}
+	}
+	sort.Sort(byPreference{matches, p})
+	return matches
+}
+
+type byPreference struct {
+	matches []Match
+	policy  *Policy
+}
+
+func (s byPreference) Len() int      { return len(s.matches) }
+func (s byPreference) Swap(i, j int) { s.matches[i], s.matches[j] = s.matches[j], s.matches[i] }
+func (s byPreference) Less(i, j int) bool {
+	a, b := s.matches[i], s.matches[j]
+	if a.Quality != b.Quality {
+		return a.Quality > b.Quality
+	}
+	if s.policy.winsTie(a, b) {
+		return true
+	}
+	if s.policy.winsTie(b, a) {
+		return false
+	}
+	if a.Fitness != b.Fitness {
+		return a.Fitness > b.Fitness
+	}
+	return a.index < b.index
+}
+
+// Finds the acceptable supported type with the highest quality,
+// breaking ties as described for BestMatch().
+func (p *Policy) bestMatch(supported []string, parsedHeader []Mime) (best Match, ok bool) {
+	return p.bestTargetMatch(supported, nil, parsedHeader)
+}
+
+// Like bestMatch() with the supported types already parsed by
+// parseTarget() into 'targets', unless it is nil.
+func (p *Policy) bestTargetMatch(supported []string, targets []Mime, parsedHeader []Mime) (best Match, ok bool) {
+	best = noMatch
+	for i, mime := range supported {
+		var m Match
+		if targets != nil {
+			m, ok = p.matchTarget(i, mime, targets[i], parsedHeader)
+		} else {
+			m, ok = p.matchSupported(i, mime, parsedHeader)
+		}
+		if ok && (m.Quality > best.Quality || (m.Quality > 0 && m.Quality == best.Quality && p.winsTie(m, best))) {
+			best = m
+		}
+	}
+	return best, best.index >= 0
+}
+
+// Like bestMatch() for the functions that negotiate on behalf of a
+// caller, which tell the observer of the policy about the outcome.
+func (p *Policy) negotiate(supported []string, parsedHeader []Mime) (best Match, ok bool) {
+	best, ok = p.bestMatch(supported, parsedHeader)
+	p.observe(best)
+	return
+}
+
+// Tells the observer of the policy, if any, about a negotiation.
+func (p *Policy) observe(best Match) {
+	if p.Observer != nil {
+		p.Observer.OnNegotiate(best, best.index >= 0)
+	}
+}
+
+// Reports whether the policy prefers 'a' over 'b' when both are
+// equally acceptable. Without any tie-breaking options neither wins,
+// leaving the order of the supported types to decide.
+func (p *Policy) winsTie(a, b Match) bool {
+	wins, _ := p.breakTie(a, b)
+	return wins
+}
+
+// Like winsTie() but also names the option that decided, or returns
+// "" if none did.
+func (p *Policy) breakTie(a, b Match) (wins bool, rule string) {
+	if p.VersionParam != "" {
+		if va, vb := p.versionRank(a), p.versionRank(b); va != vb {
+			return va > vb, "VersionParam"
+		}
+	}
+	if p.PreferVendor {
+		if va, vb := isVendorType(a.Type), isVendorType(b.Type); va != vb {
+			return va, "PreferVendor"
+		}
+	}
+	if p.ClientOrder && a.rangeIndex != b.rangeIndex {
+		return a.rangeIndex < b.rangeIndex, "ClientOrder"
+	}
+	if p.TieBreaker != nil {
+		if p.TieBreaker.Prefer(a, b) {
+			return true, "TieBreaker"
+		}
+		if p.TieBreaker.Prefer(b, a) {
+			return false, "TieBreaker"
+		}
+	}
+	return false, ""
+}
+
+// Ranks a match by the version parameter of the policy: the version
+// the range asked for beats any other, then higher versions beat lower
+// ones. Returns -1 if the range asked for no version.
+func (p *Policy) versionRank(m Match) float64 {
+	key := strings.ToLower(p.VersionParam)
+	want, ok := m.Range.params.get(key)
+	if !ok || !m.Range.isOrdinary(key) {
+		return -1
+	}
+	target, _ := ParseMimeType(m.Type)
+	if target.params.value(key) == want {
+		return math.MaxFloat64
+	}
+	if version, err := strconv.Atof64(target.params.value(key)); err == nil {
+		return version
+	}
+	return -1
+}
+
+func isVendorType(mimetype string) bool {
+	parsed, err := ParseMimeType(mimetype)
+	return err == nil && parsed.Tree() == VendorTree
+}
+
+// Returned by NegotiateType() when none of the supported mime-types
+// is acceptable, in which case a server should respond with
+// 406 Not Acceptable.
+var ErrNotAcceptable = os.NewError("No acceptable mime-type")
+
+// Like BestMatch() but returns ErrNotAcceptable instead of ""
+// when none of the supported mime-types is acceptable, including
+// when every match has been excluded with a 'q' of 0.
+// An empty header matches nothing; see NegotiateRequest() for the
+// rule that a request without an Accept header accepts anything.
+// A header exceeding the limits of the policy gives ErrTooLarge,
+// see Policy.MaxLength.
+func NegotiateType(supported []string, header string) (string, os.Error) {
+	return defaultPolicy.NegotiateType(supported, header)
+}
+
+// Like NegotiateType() but applying the options of the policy.
+func (p *Policy) NegotiateType(supported []string, header string) (string, os.Error) {
+	parsed, err := p.parseHeader(header)
+	if err == ErrTooLarge {
+		return "", err
+	}
+	best, ok := p.negotiate(supported, parsed)
+	Recycle(parsed)
+	if ok {
+		return best.Type, nil
+	}
+	return "", ErrNotAcceptable
 }
@@ -18,8 +18,8 @@ func parsedEqual(test *testing.T, mime string, t string, st string, params map[s
 	if st != r.subtype {
 		test.Errorf("%s:%d Failed to parse minor type %s from %s, got %s\n", file, line, st, mime, r.subtype)
 	}
-	if !reflect.DeepEqual(params, r.params) {
-		test.Errorf("%s:%d Failed to parse parameters, expected %v, got %v\n", file, line, params, r.params)
+	if !reflect.DeepEqual(params, r.params.toMap()) {
+		test.Errorf("%s:%d Failed to parse parameters, expected %v, got %v\n", file, line, params, r.params.toMap())
 	}
 }
 
@@ -103,3 +103,574 @@ func TestSupportWildcards(t *testing.T) {
 	}
 	bestMatch(t, supported, headers)
 }
+
+func TestClone(t *testing.T) {
+	orig, _ := ParseMediaRange("text/html;level=1")
+	c := orig.Clone()
+	c.setParam("charset", "utf-8")
+	c.setParam("level", "2")
+	if _, ok := orig.params.get("charset"); ok {
+		t.Errorf("Clone shares parameters with the original: %v", orig.params.toMap())
+	}
+	if orig.params.value("level") != "1" {
+		t.Errorf("Clone modified original level, got %s", orig.params.value("level"))
+	}
+	if c.mtype != "text" || c.subtype != "html" {
+		t.Errorf("Clone lost type, got %s/%s", c.mtype, c.subtype)
+	}
+}
+
+func TestQ(t *testing.T) {
+	cond := map[string]float64{
+		"text/html;q=0.5": 0.5,
+		"text/html;q=.2":  0.2,
+		"text/html":       1.0,
+		"text/html;q=2":   1.0,
+		"text/html;q=abc": 1.0,
+	}
+	for mime, q := range cond {
+		m, _ := ParseMimeType(mime)
+		if m.Q() != q {
+			t.Errorf("Q() of %s == %f, not %f", mime, m.Q(), q)
+		}
+	}
+}
+
+func TestSetQ(t *testing.T) {
+	m, _ := ParseMediaRange("text/html;level=1")
+	cond := map[float64]float64{
+		0.25: 0.25,
+		1.5:  1.0,
+		-1.0: 0.0,
+		0.0:  0.0,
+	}
+	for in, out := range cond {
+		m.SetQ(in)
+		if m.Q() != out {
+			t.Errorf("SetQ(%f) gave Q() == %f, not %f", in, m.Q(), out)
+		}
+	}
+	var zero Mime
+	zero.SetQ(0.5)
+	if zero.Q() != 0.5 {
+		t.Errorf("SetQ on zero Mime gave Q() == %f", zero.Q())
+	}
+}
+
+func TestParams(t *testing.T) {
+	m, _ := ParseMediaRange("text/html;level=1;q=0.4")
+	params := m.Params()
+	if !reflect.DeepEqual(params, map[string]string{"level": "1"}) {
+		t.Errorf("Params() included more than the ordinary parameters: %v", params)
+	}
+	params["level"] = "2"
+	if m.params.value("level") != "1" {
+		t.Errorf("Params() shares storage with the Mime")
+	}
+}
+
+func TestParamKeys(t *testing.T) {
+	cond := map[string][]string{
+		"multipart/mixed; boundary=xyz; charset=utf-8": {"boundary", "charset"},
+		"text/html;z=1;q=0.5;a=2;m=3":                  {"z"},
+		"text/html;z=1;a=2;m=3;q=0.5":                  {"z", "a", "m"},
+		"text/html;level=1;level=2":                    {"level"},
+		"text/html":                                    {},
+	}
+	for mime, keys := range cond {
+		// Repeat to catch any dependence on map iteration order.
+		for i := 0; i < 10; i++ {
+			m, _ := ParseMediaRange(mime)
+			if got := m.ParamKeys(); !reflect.DeepEqual(got, keys) {
+				t.Errorf("ParamKeys() of %s == %v, not %v", mime, got, keys)
+			}
+		}
+	}
+	m, _ := ParseMimeType("text/plain;b=1")
+	c := m.Clone()
+	c.setParam("a", "2")
+	if !reflect.DeepEqual(m.ParamKeys(), []string{"b"}) {
+		t.Errorf("Clone shares parameter order with the original: %v", m.ParamKeys())
+	}
+}
+
+func TestWithParam(t *testing.T) {
+	m, _ := ParseMediaRange("application/json;q=0.8")
+	derived := m.WithoutParam("q").WithParam("Charset", "utf-8")
+	if !reflect.DeepEqual(derived.params.toMap(), map[string]string{"charset": "utf-8"}) {
+		t.Errorf("Failed to derive parameters, got %v", derived.params.toMap())
+	}
+	if !reflect.DeepEqual(derived.ParamKeys(), []string{"charset"}) {
+		t.Errorf("Failed to derive parameter order, got %v", derived.ParamKeys())
+	}
+	if derived.mtype != "application" || derived.subtype != "json" {
+		t.Errorf("Failed to keep type, got %s/%s", derived.mtype, derived.subtype)
+	}
+	if !reflect.DeepEqual(m.params.toMap(), map[string]string{"q": "0.8"}) {
+		t.Errorf("Builders modified the original, got %v", m.params.toMap())
+	}
+	replaced := derived.WithParam("charset", "latin1")
+	if replaced.params.value("charset") != "latin1" || derived.params.value("charset") != "utf-8" {
+		t.Errorf("WithParam failed to replace a value without side effects")
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	cond := []struct {
+		mime               string
+		wildcard, concrete bool
+		hasParams          bool
+	}{
+		{"*/*", true, false, false},
+		{"*", true, false, false},
+		{"text/*", true, false, false},
+		{"text/html", false, true, false},
+		{"text/html;q=0.5", false, true, false},
+		{"text/html;level=1", false, true, true},
+	}
+	for _, c := range cond {
+		m, _ := ParseMediaRange(c.mime)
+		if m.IsWildcard() != c.wildcard {
+			t.Errorf("IsWildcard() of %s == %v", c.mime, m.IsWildcard())
+		}
+		if m.IsConcrete() != c.concrete {
+			t.Errorf("IsConcrete() of %s == %v", c.mime, m.IsConcrete())
+		}
+		if m.HasParams() != c.hasParams {
+			t.Errorf("HasParams() of %s == %v", c.mime, m.HasParams())
+		}
+	}
+	m, _ := ParseMediaRange("text/html;Level=1")
+	if !m.HasParam("level") || !m.HasParam("LEVEL") || m.HasParam("charset") {
+		t.Errorf("HasParam failed on %v", m.params.toMap())
+	}
+	var zero Mime
+	if zero.IsConcrete() || zero.IsWildcard() {
+		t.Errorf("Zero Mime should be neither concrete nor a wildcard")
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	accept := "text/*;q=0.9, text/html;q=0.5, */*;q=0.1, text/html;level=1;q=0.2, text/html;level=1;charset=utf-8;q=0.3"
+	cond := map[string]float{
+		"text/html":                       0.5,
+		"text/plain":                      0.9,
+		"image/png":                       0.1,
+		"text/html;level=1":               0.2,
+		"text/html;charset=utf-8;level=1": 0.3,
+		"text/html;level=2":               0.5,
+		"text/html;charset=utf-8":         0.5,
+	}
+	for mime, q := range cond {
+		if got := Quality(mime, accept); got != q {
+			t.Errorf("Quality(%s) == %f, not %f", mime, got, q)
+		}
+	}
+	bestMatch(t, []string{"text/html", "text/plain"}, map[string]string{
+		"text/*;q=0.9, text/html;q=0.5":   "text/plain",
+		"text/html;q=0.5, text/*;q=0.9":   "text/plain",
+		"text/html;level=1, text/*;q=0.2": "text/html",
+	})
+}
+
+func TestExclusion(t *testing.T) {
+	supported := []string{"application/json", "text/html"}
+	headers := map[string]string{
+		"application/json;q=0":                   "",
+		"application/json;q=0, */*":              "text/html",
+		"application/json;q=0, application/*":    "",
+		"*/*;q=0, application/json;q=0.1":        "application/json",
+		"text/*;q=0, text/html;q=0.0, */*;q=0.5": "application/json",
+	}
+	bestMatch(t, supported, headers)
+	for header, result := range headers {
+		mime, err := NegotiateType(supported, header)
+		if result == "" && err != ErrNotAcceptable {
+			t.Errorf("NegotiateType(%v, %s) == %s, %v; expected ErrNotAcceptable", supported, header, mime, err)
+		}
+		if result != "" && (err != nil || mime != result) {
+			t.Errorf("NegotiateType(%v, %s) == %s, %v; expected %s", supported, header, mime, err, result)
+		}
+	}
+	if _, err := NegotiateType(nil, "*/*"); err != ErrNotAcceptable {
+		t.Errorf("NegotiateType with nothing supported should fail")
+	}
+}
+
+func TestBestMatchServerOrder(t *testing.T) {
+	headers := map[string]string{
+		"*/*":                                  "text/html",
+		"text/html, application/rdf+xml":       "text/html",
+		"application/rdf+xml, text/html":       "text/html",
+		"application/*;q=0.5, text/*;q=0.5":    "text/html",
+		"application/rdf+xml;q=0.9, */*;q=0.9": "text/html",
+	}
+	for i := 0; i < 10; i++ {
+		bestMatch(t, []string{"text/html", "application/rdf+xml"}, headers)
+	}
+	bestMatch(t, []string{"application/rdf+xml", "text/html"}, map[string]string{
+		"*/*":                            "application/rdf+xml",
+		"text/html, application/rdf+xml": "application/rdf+xml",
+	})
+}
+
+func TestBestMatchWithQuality(t *testing.T) {
+	supported := []string{"application/json", "text/html"}
+	cond := []struct {
+		header  string
+		mime    string
+		quality float
+		fitness int
+	}{
+		{"text/html;q=0.7, application/*;q=0.3", "text/html", 0.7, 111},
+		{"application/*;q=0.3", "application/json", 0.3, 101},
+		{"*/*;q=0.1", "application/json", 0.1, 1},
+		{"image/png", "", 0.0, -1},
+		{"application/json;q=0", "", 0.0, -1},
+	}
+	for _, c := range cond {
+		mime, quality, fitness := BestMatchWithQuality(supported, c.header)
+		if mime != c.mime || quality != c.quality || fitness != c.fitness {
+			t.Errorf("BestMatchWithQuality(%v, %s) == %s, %f, %d; not %s, %f, %d",
+				supported, c.header, mime, quality, fitness, c.mime, c.quality, c.fitness)
+		}
+	}
+}
+
+func TestBestMatchRange(t *testing.T) {
+	supported := []string{"application/json", "text/html;charset=utf-8"}
+	m, ok := BestMatchRange(supported, "application/json;q=0.2, text/*;charset=utf-8;q=0.5")
+	if !ok || m.Type != "text/html;charset=utf-8" || m.Quality != 0.5 {
+		t.Fatalf("BestMatchRange chose %v", m)
+	}
+	if m.Range.Type() != "text" || m.Range.Subtype() != "*" || m.Range.params.value("charset") != "utf-8" {
+		t.Errorf("BestMatchRange returned the wrong range %v", m.Range)
+	}
+	if m, ok := BestMatchRange(supported, "image/*"); ok || m.Type != "" {
+		t.Errorf("BestMatchRange should not match, got %v", m)
+	}
+}
+
+func matchTypes(matches []Match) []string {
+	types := make([]string, len(matches))
+	for i, m := range matches {
+		types[i] = m.Type
+	}
+	return types
+}
+
+func TestAllMatches(t *testing.T) {
+	supported := []string{"application/json", "text/html", "text/plain", "image/png"}
+	cond := map[string][]string{
+		"text/*;q=0.5, application/json, image/png;q=0": {"application/json", "text/html", "text/plain"},
+		"*/*;q=0.5, text/plain;q=0.5":                   {"text/plain", "application/json", "text/html", "image/png"},
+		"*/*":                                           {"application/json", "text/html", "text/plain", "image/png"},
+		"video/*":                                       {},
+	}
+	for header, want := range cond {
+		if got := matchTypes(AllMatches(supported, header)); !reflect.DeepEqual(got, want) {
+			t.Errorf("AllMatches(%v, %s) == %v, not %v", supported, header, got, want)
+		}
+	}
+	p := &Policy{ClientOrder: true}
+	header := "image/*;q=0.5, text/plain;q=0.5, */*;q=0.5"
+	want := []string{"image/png", "text/plain", "application/json", "text/html"}
+	if got := matchTypes(p.AllMatches(supported, header)); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllMatches(%v, %s) with client order == %v, not %v", supported, header, got, want)
+	}
+}
+
+func TestAcceptExt(t *testing.T) {
+	m, _ := ParseMediaRange("text/html;level=1;q=0.5;foo=bar;baz")
+	if !reflect.DeepEqual(m.Params(), map[string]string{"level": "1"}) {
+		t.Errorf("Params() included accept-ext, got %v", m.Params())
+	}
+	if !reflect.DeepEqual(m.ParamKeys(), []string{"level"}) {
+		t.Errorf("ParamKeys() included accept-ext, got %v", m.ParamKeys())
+	}
+	if !reflect.DeepEqual(m.AcceptExt(), map[string]string{"foo": "bar", "baz": ""}) {
+		t.Errorf("AcceptExt() == %v", m.AcceptExt())
+	}
+	if m.String() != `text/html;level=1;q=0.5;foo=bar;baz=""` {
+		t.Errorf("String() lost accept-ext, got %s", m.String())
+	}
+	if c := m.WithoutParam("level").WithParam("charset", "utf-8"); !reflect.DeepEqual(c.AcceptExt(), m.AcceptExt()) {
+		t.Errorf("Builders lost accept-ext, got %v", c.AcceptExt())
+	}
+	if n, _ := ParseMediaRange("text/html;foo=bar"); len(n.AcceptExt()) != 0 {
+		t.Errorf("Parameters without a q are not accept-ext, got %v", n.AcceptExt())
+	}
+	header := "text/html;level=1;q=0.5;level=2"
+	if n, _ := ParseMediaRange(header); !reflect.DeepEqual(n.Params(), map[string]string{"level": "1"}) || len(n.AcceptExt()) != 0 {
+		t.Errorf("ParseMediaRange(%s) == %v, %v", header, n.Params(), n.AcceptExt())
+	}
+	if fitness, _ := FitnessAndQuality("text/html;level=2", ParseHeader(header)); fitness >= 0 {
+		t.Errorf("FitnessAndQuality(text/html;level=2, %s) == %d, ignoring level=1", header, fitness)
+	}
+
+	accept := "text/html;q=0.5;foo=bar, text/*;q=0.1"
+	cond := map[string]float{
+		"text/html":         0.5,
+		"text/html;foo=baz": 0.5,
+		"text/plain":        0.1,
+	}
+	for mime, q := range cond {
+		if got := Quality(mime, accept); got != q {
+			t.Errorf("Quality(%s, %s) == %f, not %f", mime, accept, got, q)
+		}
+	}
+}
+
+func TestQuotedParams(t *testing.T) {
+	parsedEqual(t, `text/plain; title="a;b,c"`, "text", "plain", map[string]string{"title": "a;b,c", "q": "1"})
+	parsedEqual(t, `text/plain; title="say \"hi\"";charset=utf-8`, "text", "plain",
+		map[string]string{"title": `say "hi"`, "charset": "utf-8", "q": "1"})
+	parsedEqual(t, `text/plain;a="x\\";b=2`, "text", "plain", map[string]string{"a": `x\`, "b": "2", "q": "1"})
+	parsedEqual(t, `text/plain;a="  spaced  "`, "text", "plain", map[string]string{"a": "  spaced  ", "q": "1"})
+	parsedEqual(t, `text/plain;a="unterminated;b=2`, "text", "plain", map[string]string{"a": "unterminated;b=2", "q": "1"})
+	parsedEqual(t, `text/plain;q="0.5"`, "text", "plain", map[string]string{"q": "0.5"})
+	m, _ := ParseMimeType(`text/plain; title="a;b \"c\""`)
+	if m.String() != `text/plain;title="a;b \"c\""` {
+		t.Errorf("String() failed to quote, got %s", m.String())
+	}
+}
+
+func TestParseHeaderQuotedCommas(t *testing.T) {
+	parsed := ParseHeader(`text/html;title="a, b";q=0.5, application/json, text/plain;x="\","`)
+	if len(parsed) != 3 {
+		t.Fatalf("ParseHeader split into %d ranges: %v", len(parsed), parsed)
+	}
+	if parsed[0].params.value("title") != "a, b" || parsed[0].Q() != 0.5 {
+		t.Errorf("Failed to parse first range, got %v", parsed[0].params.toMap())
+	}
+	if parsed[1].mtype != "application" || parsed[1].subtype != "json" {
+		t.Errorf("Failed to parse second range, got %v", parsed[1])
+	}
+	if parsed[2].params.value("x") != `",` {
+		t.Errorf("Failed to parse escaped quote, got %v", parsed[2].params.toMap())
+	}
+}
+
+func TestSupportedParams(t *testing.T) {
+	supported := []string{"application/vnd.api+json;version=2", "application/vnd.api+json;version=1", "text/html"}
+	headers := map[string]string{
+		"application/vnd.api+json;version=1":                    "application/vnd.api+json;version=1",
+		"application/vnd.api+json;version=2":                    "application/vnd.api+json;version=2",
+		"application/vnd.api+json; version=1, text/html;q=0.5":  "application/vnd.api+json;version=1",
+		"application/vnd.api+json;version=3, text/html;q=0.5":   "text/html",
+		"application/vnd.api+json":                              "application/vnd.api+json;version=2",
+		"application/vnd.api+json;version=1;q=0.5, */*;q=0.9":   "application/vnd.api+json;version=2",
+		"application/vnd.api+json;version=1, application/*;q=0": "application/vnd.api+json;version=1",
+	}
+	bestMatch(t, supported, headers)
+}
+
+func TestBestMatchWithDefault(t *testing.T) {
+	supported := []string{"application/json", "text/html"}
+	cond := map[string]string{
+		"text/html":            "text/html",
+		"image/png":            "application/xml",
+		"application/json;q=0": "application/xml",
+		"":                     "application/xml",
+	}
+	for header, result := range cond {
+		if got := BestMatchWithDefault(supported, header, "application/xml"); got != result {
+			t.Errorf("BestMatchWithDefault(%v, %s) == %s, not %s", supported, header, got, result)
+		}
+	}
+	p := &Policy{NormalizeAliases: true}
+	if got := p.BestMatchWithDefault(supported, "text/json", "text/plain"); got != "application/json" {
+		t.Errorf("Policy.BestMatchWithDefault ignored the policy, got %s", got)
+	}
+}
+
+func TestSuffixWildcard(t *testing.T) {
+	supported := []string{"application/*+json", "application/xml"}
+	headers := map[string]string{
+		"application/vnd.api+json":                        "application/*+json",
+		"application/vnd.api+json;q=0.5, application/xml": "application/xml",
+		"application/atom+xml":                            "",
+		"application/json":                                "",
+		"application/*":                                   "application/*+json",
+		"text/vnd.api+json":                               "",
+		"application/*+json":                              "application/*+json",
+	}
+	bestMatch(t, supported, headers)
+	if q := Quality("application/*+json", "application/*;q=0.2, application/vnd.a+json;q=0.7"); q != 0.7 {
+		t.Errorf("A suffix match should outrank a subtype wildcard, got %f", q)
+	}
+	m, _ := ParseMediaRange("application/*+json")
+	if !m.IsWildcard() || m.IsConcrete() {
+		t.Errorf("application/*+json should be a wildcard")
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	supported := []string{"application/vnd.api+json", "text/html;level=1;charset=utf-8", "image/png"}
+	tests := map[string]Specificity{
+		"text/html, */*;q=0.1": ExactMatch,
+		"text/*":               TypeMatch,
+		"*/*":                  AnyMatch,
+		"application/*+json":   PartialMatch,
+		"audio/*":              NoMatch,
+	}
+	for header, want := range tests {
+		m, _ := BestMatchRange(supported, header)
+		if m.Specificity != want {
+			t.Errorf("BestMatchRange(%s).Specificity == %v, not %v", header, m.Specificity, want)
+		}
+	}
+	m, _ := BestMatchRange(supported, "text/html;level=1;charset=utf-8")
+	if m.Specificity != ExactMatch || m.Params != 2 {
+		t.Errorf("BestMatchRange() == %v with %d params, not exact with 2", m.Specificity, m.Params)
+	}
+	if ExactMatch.String() != "exact" || Specificity(9).String() != "invalid" {
+		t.Errorf("Specificity.String() == %s, %s", ExactMatch, Specificity(9))
+	}
+}
+
+func TestBestMatchConcrete(t *testing.T) {
+	supported := []string{"image/*", "text/html"}
+	cond := map[string]string{
+		"image/png":                         "image/png",
+		"text/html":                         "text/html",
+		"image/*;q=0.9, image/png;q=0.5":    "image/png",
+		"image/gif;q=0.2, image/webp;q=0.7": "image/webp",
+		"image/svg+xml;q=1;a=b":             "image/svg+xml",
+		"*/*":                               "",
+		"image/png;q=0, image/*":            "",
+		"audio/ogg":                         "",
+	}
+	for header, want := range cond {
+		if got := BestMatchConcrete(supported, header); got != want {
+			t.Errorf("BestMatchConcrete(%s) == %s, not %s", header, got, want)
+		}
+	}
+}
+
+func TestBestMatchIndex(t *testing.T) {
+	supported := []string{"application/json", "text/html", "application/json"}
+	cond := map[string]int{
+		"text/*":                   1,
+		"application/json":         0,
+		"*/*":                      0,
+		"text/html;q=0, image/png": -1,
+	}
+	for header, want := range cond {
+		if got := BestMatchIndex(supported, header); got != want {
+			t.Errorf("BestMatchIndex(%s) == %d, not %d", header, got, want)
+		}
+	}
+	p := &Policy{ClientOrder: true}
+	if got := p.BestMatchIndex(supported, "text/html, application/json"); got != 1 {
+		t.Errorf("ClientOrder BestMatchIndex() == %d, not 1", got)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	supported := []string{"text/html;level=1;charset=utf-8", "text/html;charset=utf-8;level=1", "text/plain;a=1;b=2;c=3", "text/plain;c=3;b=2;a=1", "text/*", "application/json"}
+	headers := []string{
+		"text/*, */*;q=0.5",
+		"text/html;charset=utf-8;level=1, text/plain;b=2;a=1",
+		"text/plain;a=1;b=2;c=3;q=0.5, text/html;level=1;q=0.5, application/*;q=0.5",
+	}
+	for _, header := range headers {
+		best, all := BestMatch(supported, header), matchTypes(AllMatches(supported, header))
+		for i := 0; i < 100; i++ {
+			if got := BestMatch(supported, header); got != best {
+				t.Fatalf("BestMatch(%s) == %s, then %s", header, best, got)
+			}
+			if got := matchTypes(AllMatches(supported, header)); !reflect.DeepEqual(got, all) {
+				t.Fatalf("AllMatches(%s) == %v, then %v", header, all, got)
+			}
+		}
+	}
+}
+
+func TestMultipleQ(t *testing.T) {
+	for _, p := range []*Policy{defaultPolicy, &Policy{Strict: true}, &Policy{RepeatedParams: LastParamWins}} {
+		m, err := p.ParseMediaRange("text/html;q=0.5;level=1;q=0.9")
+		if err != nil || m.Q() != 0.5 || m.String() != "text/html;q=0.5;level=1" {
+			t.Errorf("ParseMediaRange() == %s, %v", m, err)
+		}
+		if ext := m.AcceptExt(); len(ext) != 1 || ext["level"] != "1" {
+			t.Errorf("AcceptExt() == %v", ext)
+		}
+	}
+	header := "text/html;q=0.1;q=1, application/json;q=0.5"
+	if got := BestMatch([]string{"text/html", "application/json"}, header); got != "application/json" {
+		t.Errorf("BestMatch(%s) == %s", header, got)
+	}
+}
+
+func TestTokenValidation(t *testing.T) {
+	cond := []struct {
+		in, out string
+		strict  bool
+	}{
+		{"text/html<script>", "text/html;q=1", false},
+		{"text/ht ml", "text/ht;q=1", false},
+		{"text/html; le<vel>=1", "text/html;le=1;q=1", false},
+		{"text/html;", "text/html;q=1", true},
+		{"text/html; ;level=1", "text/html;level=1;q=1", true},
+		{"application/vnd.a+json;x", "application/vnd.a+json;x=\"\";q=1", true},
+	}
+	strict := &Policy{Strict: true}
+	for _, c := range cond {
+		m, err := ParseMediaRange(c.in)
+		if err != nil || m.String() != c.out {
+			t.Errorf("ParseMediaRange(%s) == %s, %v, not %s", c.in, m, err, c.out)
+		}
+		if _, err := strict.ParseMediaRange(c.in); (err == nil) != c.strict {
+			t.Errorf("Strict ParseMediaRange(%s) error == %v", c.in, err)
+		}
+	}
+	for _, in := range []string{"<script>/html", "text/", "/html", "text/@"} {
+		if _, err := ParseMediaRange(in); err == nil {
+			t.Errorf("ParseMediaRange(%s) did not fail", in)
+		}
+	}
+}
+
+func TestParseHeaderWithErrors(t *testing.T) {
+	parsed, err := ParseHeaderWithErrors("text/html, nonsense, , application/json;q=0.5, /xml")
+	if len(parsed) != 2 || parsed[0].String() != "text/html;q=1" || parsed[1].String() != "application/json;q=0.5" {
+		t.Errorf("ParseHeaderWithErrors() ranges == %v", parsed)
+	}
+	errs, ok := err.(HeaderError)
+	if !ok || len(errs) != 2 || errs[0].Index != 1 || errs[0].Range != "nonsense" || errs[1].Index != 4 || errs[1].Range != "/xml" {
+		t.Fatalf("ParseHeaderWithErrors() error == %v", err)
+	}
+	if s := errs.String(); s != "Media-range 1 \"nonsense\": Not a valid mimetype; Media-range 4 \"/xml\": Not a valid mimetype" {
+		t.Errorf("HeaderError.String() == %s", s)
+	}
+	if parsed, err := ParseHeaderWithErrors("text/html, , text/plain"); err != nil || len(parsed) != 2 {
+		t.Errorf("ParseHeaderWithErrors() with an empty element == %v, %v", parsed, err)
+	}
+	if _, err := ParseHeaderWithErrors("text/html\r\n"); err != ErrControlChar {
+		t.Errorf("ParseHeaderWithErrors() with a CR LF error == %v", err)
+	}
+	strict := &Policy{Strict: true}
+	if _, err := strict.ParseHeaderWithErrors("text/html;q=0.12345"); err == nil {
+		t.Errorf("Strict ParseHeaderWithErrors() did not fail")
+	}
+}
+
+func BenchmarkParseMediaRange(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ParseMediaRange("text/html")
+	}
+}
+
+func BenchmarkParseHeader(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ParseHeader("text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8")
+	}
+}
+
+func BenchmarkBestMatch(b *testing.B) {
+	supported := []string{"application/json", "text/html"}
+	for i := 0; i < b.N; i++ {
+		BestMatch(supported, "text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8")
+	}
+}
@@ -0,0 +1,82 @@
+package mimeparse
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+)
+
+// The characters RFC 2046 allows in a multipart boundary, besides
+// letters and digits. A space is allowed too, but not at the end.
+const boundaryChars = "'()+_,-./:=? "
+
+// Reports whether 'boundary' is a valid multipart boundary under RFC
+// 2046: 1 to 70 letters, digits or characters from "'()+_,-./:=? ",
+// not ending in a space.
+//
+// ValidBoundary('simple boundary')
+// true
+// ValidBoundary('ends with space ')
+// false
+func ValidBoundary(boundary string) bool {
+	if len(boundary) < 1 || len(boundary) > 70 || boundary[len(boundary)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(boundary); i++ {
+		c := boundary[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.Index(boundaryChars, string(c)) >= 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the boundary parameter of a multipart type, which RFC 2046
+// requires. Returns an error if the type is not multipart or the
+// boundary is missing or invalid, see ValidBoundary().
+func (m Mime) Boundary() (string, os.Error) {
+	if !m.IsMultipart() {
+		return "", os.NewError("Not a multipart type: " + m.String())
+	}
+	boundary, ok := m.params.get("boundary")
+	if !ok {
+		return "", os.NewError("Missing boundary parameter")
+	}
+	if !ValidBoundary(boundary) {
+		return "", os.NewError("Invalid boundary parameter: " + boundary)
+	}
+	return boundary, nil
+}
+
+// Returns a random boundary for a multipart body, 60 hexadecimal
+// digits from crypto/rand like mime/multipart uses, so it is valid
+// and needs no quoting.
+func NewBoundary() string {
+	var b [30]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%x", b[:])
+}
+
+// Returns a mime/multipart reader for a body of the multipart type,
+// reading the parts between its boundaries. Returns an error as
+// Boundary() does if the type has no valid boundary.
+func (m Mime) MultipartReader(body io.Reader) (*multipart.Reader, os.Error) {
+	boundary, err := m.Boundary()
+	if err != nil {
+		return nil, err
+	}
+	return multipart.NewReader(body, boundary), nil
+}
+
+// Returns the Content-Type of a body written by a mime/multipart
+// writer, such as 'multipart/mixed; boundary=...' for the subtype
+// 'mixed', quoting the boundary if needed. Returns "" if the subtype
+// is not a token.
+func MultipartContentType(subtype string, w *multipart.Writer) string {
+	return FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()})
+}
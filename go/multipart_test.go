@@ -0,0 +1,86 @@
+package mimeparse
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestValidBoundary(t *testing.T) {
+	cond := map[string]bool{
+		"simple boundary":       true,
+		"gc0pJq0M:08jU534c0p":   true,
+		"'()+_,-./:=?":          true,
+		"":                      false,
+		"ends with space ":      false,
+		"semi;colon":            false,
+		"quote\"":               false,
+		strings.Repeat("a", 70): true,
+		strings.Repeat("a", 71): false,
+	}
+	for boundary, expected := range cond {
+		if result := ValidBoundary(boundary); result != expected {
+			t.Errorf("ValidBoundary(%s) == %v, not %v", boundary, result, expected)
+		}
+	}
+}
+
+func TestBoundary(t *testing.T) {
+	cond := map[string]string{
+		"multipart/mixed; boundary=xyz":              "xyz",
+		"multipart/form-data; boundary=\"a b:c\"":    "a b:c",
+		"multipart/mixed":                            "",
+		"multipart/mixed; boundary=\"bad;boundary\"": "",
+		"text/plain; boundary=xyz":                   "",
+	}
+	for mimetype, expected := range cond {
+		m, _ := ParseMimeType(mimetype)
+		boundary, err := m.Boundary()
+		if boundary != expected || (err == nil) != (expected != "") {
+			t.Errorf("Boundary(%s) == %s, %v, not %s", mimetype, boundary, err, expected)
+		}
+	}
+}
+
+func TestNewBoundary(t *testing.T) {
+	a, b := NewBoundary(), NewBoundary()
+	if !ValidBoundary(a) || len(a) != 60 || a == b {
+		t.Errorf("NewBoundary() == %s, %s", a, b)
+	}
+	m, _ := ParseMimeType(MultipartFormData + "; boundary=" + a)
+	if boundary, err := m.Boundary(); boundary != a || err != nil {
+		t.Errorf("Boundary() of a new boundary == %s, %v", boundary, err)
+	}
+}
+
+func TestMultipartBridge(t *testing.T) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, _ := w.CreateFormFile("upload", "a.txt")
+	part.Write([]byte("hello"))
+	w.Close()
+	contentType := MultipartContentType("form-data", w)
+	if !strings.HasPrefix(contentType, "multipart/form-data;boundary=") {
+		t.Errorf("MultipartContentType() == %s", contentType)
+	}
+	m, _, err := ParseContentType(contentType)
+	if err != nil {
+		t.Fatalf("ParseContentType(%s) failed: %v", contentType, err)
+	}
+	r, err := m.MultipartReader(body)
+	if err != nil {
+		t.Fatalf("MultipartReader() failed: %v", err)
+	}
+	p, err := r.NextPart()
+	if err != nil {
+		t.Fatalf("NextPart() failed: %v", err)
+	}
+	if data, _ := ioutil.ReadAll(p); string(data) != "hello" || p.FormName() != "upload" {
+		t.Errorf("NextPart() == %s, %s", p.FormName(), data)
+	}
+	if _, err := mustParse(t, "multipart/mixed").MultipartReader(body); err == nil {
+		t.Errorf("MultipartReader() without a boundary did not fail")
+	}
+}
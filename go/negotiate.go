@@ -0,0 +1,274 @@
+package mimeparse
+
+import (
+	"http"
+	"os"
+	"strings"
+)
+
+// What a server is able to produce, in order of its preference.
+// Leave a field empty to skip negotiating that aspect.
+type Offers struct {
+	Types     []string // mime-types, see BestMatch()
+	Charsets  []string // see BestCharset()
+	Languages []string // language tags, see BestLanguage()
+	Encodings []string // content-codings, see BestEncoding()
+}
+
+// The outcome of Negotiate(), ready to be used in the Content-Type,
+// Content-Language and Content-Encoding headers of a response.
+type NegotiationResult struct {
+	Type     string
+	Charset  string // "" if the type takes no charset
+	Language string
+	Encoding string
+}
+
+// Returns the quality of a charset under an Accept-Charset header
+// that has already been parsed. Charsets not named get the 'q' of
+// '*', or 0 if there is none.
+func charsetQuality(charset string, accepted []weighted) float {
+	charset = NormalizeCharset(charset)
+	star := 0.0
+	for _, w := range accepted {
+		if NormalizeCharset(w.value) == charset {
+			return w.q
+		}
+		if w.value == "*" {
+			star = w.q
+		}
+	}
+	return star
+}
+
+// Takes a list of supported charsets and finds the one most
+// acceptable to an Accept-Charset header, preferring the one listed
+// first among equals. Charset names are compared without regard to
+// case and aliases, see NormalizeCharset(). An empty header accepts
+// any charset. Returns "" if none is acceptable.
+//
+// BestCharset(['utf-8', 'iso-8859-1'], 'iso-8859-1, utf-8;q=0.5')
+// 'iso-8859-1'
+func BestCharset(supported []string, header string) string {
+	if strings.TrimSpace(header) == "" {
+		header = "*"
+	}
+	return bestWeighted(supported, parseWeighted(header), charsetQuality)
+}
+
+// Returns the quality of a language tag under an Accept-Language
+// header that has already been parsed, using the basic filtering of
+// RFC 4647 section 3.3.1: a range matches a tag that equals it or
+// starts with it followed by '-', and the longest matching range
+// gives the quality. '*' matches every tag.
+func languageQuality(tag string, accepted []weighted) float {
+	tag = strings.ToLower(tag)
+	longest, q := -1, 0.0
+	for _, w := range accepted {
+		length := len(w.value)
+		switch {
+		case w.value == "*":
+			length = 0
+		case w.value != tag && !strings.HasPrefix(tag, w.value+"-"):
+			continue
+		}
+		if length > longest {
+			longest, q = length, w.q
+		}
+	}
+	return q
+}
+
+// Takes a list of supported language tags and finds the one most
+// acceptable to an Accept-Language header, preferring the one listed
+// first among equals. A range such as 'en' accepts 'en-GB', but not
+// the other way around. An empty header accepts any language.
+// Returns "" if none is acceptable.
+//
+// BestLanguage(['en-US', 'de-DE'], 'de, en;q=0.5')
+// 'de-DE'
+func BestLanguage(supported []string, header string) string {
+	if strings.TrimSpace(header) == "" {
+		header = "*"
+	}
+	return bestWeighted(supported, parseWeighted(header), languageQuality)
+}
+
+// Returns the entry of 'supported' with the highest non-zero quality,
+// the first one among equals, or "" if none is acceptable.
+func bestWeighted(supported []string, accepted []weighted, quality func(string, []weighted) float) string {
+	best, bestQ := "", 0.0
+	for _, s := range supported {
+		if q := quality(s, accepted); q > bestQ {
+			best, bestQ = s, q
+		}
+	}
+	return best
+}
+
+// Reports whether a charset is meaningful for the mime-type, as it
+// is for text and XML.
+func takesCharset(m Mime) bool {
+	return m.mtype == "text" || m.subtype == "xml" || m.Suffix() == "xml"
+}
+
+// Negotiates every aspect of a response at once from the Accept,
+// Accept-Charset, Accept-Language and Accept-Encoding headers of a
+// request. A missing header accepts anything, and so does an empty
+// one except for Accept-Encoding, which then only accepts 'identity'.
+//
+// The charset follows from the chosen type: a type offered with a
+// charset parameter, such as 'text/html;charset=utf-8', fixes the
+// charset, while types that take no charset, such as 'image/png',
+// leave it empty. Otherwise the charset is negotiated from
+// Offers.Charsets.
+//
+// Returns ErrNotAcceptable if any of the offered aspects has no
+// acceptable value, leaving that field of the result empty while
+// the others are still negotiated.
+func Negotiate(h http.Header, offers Offers) (NegotiationResult, os.Error) {
+	return defaultPolicy.Negotiate(h, offers)
+}
+
+// Like Negotiate() but applying the options of the policy to the
+// Accept header.
+func (p *Policy) Negotiate(h http.Header, offers Offers) (NegotiationResult, os.Error) {
+	n := &Negotiator{policy: p, header: h}
+	var err os.Error
+	check := func(offered []string, chosen string) string {
+		if len(offered) > 0 && chosen == "" {
+			err = ErrNotAcceptable
+		}
+		return chosen
+	}
+	result := NegotiationResult{}
+	charset := true
+	if len(offers.Types) > 0 {
+		result.Type = check(offers.Types, n.Type(offers.Types...))
+		mime, _ := ParseMimeType(result.Type)
+		if mime.HasParam("charset") {
+			result.Charset = mime.params.value("charset")
+		}
+		charset = result.Type != "" && result.Charset == "" && takesCharset(mime)
+	}
+	if charset {
+		result.Charset = check(offers.Charsets, n.Charset(offers.Charsets...))
+	}
+	result.Language = check(offers.Languages, n.Language(offers.Languages...))
+	result.Encoding = check(offers.Encodings, n.Encoding(offers.Encodings...))
+	return result, err
+}
+
+// Negotiates the response to a single request. Each of the Accept,
+// Accept-Charset, Accept-Language and Accept-Encoding headers is
+// parsed the first time it is needed and then reused, so a handler
+// can ask for the best type, charset, language and encoding in any
+// order and as often as it likes:
+//
+// n := NewNegotiator(req)
+// switch n.Type("application/json", "text/html") { ... }
+type Negotiator struct {
+	policy    *Policy
+	header    http.Header
+	accept    []Mime
+	charsets  []weighted
+	languages []weighted
+	encodings []weighted
+}
+
+// Returns a Negotiator for the request.
+func NewNegotiator(req *http.Request) *Negotiator {
+	return defaultPolicy.NewNegotiator(req)
+}
+
+// Like NewNegotiator() but applying the options of the policy to
+// the Accept header.
+func (p *Policy) NewNegotiator(req *http.Request) *Negotiator {
+	return &Negotiator{policy: p, header: req.Header}
+}
+
+// Returns the supported mime-type most acceptable to the request as
+// BestMatch() does, or "" if none is. A missing or empty Accept header
+// accepts any type.
+func (n *Negotiator) Type(supported ...string) string {
+	if n.accept == nil {
+		n.accept = n.policy.ParseAcceptHeader(n.header)
+	}
+	best, _ := n.policy.negotiate(supported, n.accept)
+	return best.Type
+}
+
+// Returns the supported charset most acceptable to the request as
+// BestCharset() does, or "" if none is.
+func (n *Negotiator) Charset(supported ...string) string {
+	if n.charsets == nil {
+		n.charsets = parseRequestWeighted(n.header, "Accept-Charset")
+	}
+	return bestWeighted(supported, n.charsets, charsetQuality)
+}
+
+// Returns the supported language tag most acceptable to the request
+// as BestLanguage() does, or "" if none is.
+func (n *Negotiator) Language(supported ...string) string {
+	if n.languages == nil {
+		n.languages = parseRequestWeighted(n.header, "Accept-Language")
+	}
+	return bestWeighted(supported, n.languages, languageQuality)
+}
+
+// Returns the supported content-coding most acceptable to the request
+// as BestEncoding() does, or "" if none is. A request without an
+// Accept-Encoding header accepts any coding, while an empty one only
+// accepts 'identity'.
+func (n *Negotiator) Encoding(supported ...string) string {
+	if n.encodings == nil {
+		values, ok := n.header["Accept-Encoding"]
+		if !ok {
+			values = []string{"*"}
+		}
+		n.encodings = parseWeighted(strings.Join(values, ","))
+	}
+	return bestWeighted(supported, n.encodings, encodingQuality)
+}
+
+// Parses a header of weighted values that may appear on several
+// lines, taking a missing or empty header to accept anything.
+func parseRequestWeighted(h http.Header, key string) []weighted {
+	list := parseWeighted(strings.Join(h[key], ","))
+	if len(list) == 0 {
+		list = parseWeighted("*")
+	}
+	return list
+}
+
+// Splits a comma separated list header, such as Sec-WebSocket-Protocol,
+// into its elements with surrounding whitespace removed. Empty
+// elements are skipped and commas inside quoted-strings do not
+// separate elements, as in ParseHeader().
+func ParseList(header string) []string {
+	list := []string{}
+	for _, element := range splitQuoted(header, ',') {
+		if element = strings.TrimSpace(element); element != "" {
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+// Negotiates a list header without weights, where the client lists
+// the values it supports in order of preference, as it does with
+// WebSocket subprotocols. Returns the first value of the header that
+// is in 'supported', comparing them exactly, or "" if there is none.
+//
+// NegotiateList(['chat', 'superchat'], 'superchat, chat')
+// 'superchat'
+func NegotiateList(supported []string, header string) string {
+	for _, value := range ParseList(header) {
+		for _, s := range supported {
+			if s == value {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package mimeparse
+
+import (
+	"http"
+	"testing"
+)
+
+func TestBestCharset(t *testing.T) {
+	supported := []string{"utf-8", "iso-8859-1"}
+	cond := map[string]string{
+		"iso-8859-1, utf-8;q=0.5": "iso-8859-1",
+		"UTF-8":                   "utf-8",
+		"":                        "utf-8",
+		"*;q=0.1, utf-8;q=0":      "iso-8859-1",
+		"us-ascii":                "",
+		"UTF8, latin1;q=0.5":      "utf-8",
+		"utf8;q=0.2, Latin-1":     "iso-8859-1",
+	}
+	for header, want := range cond {
+		if got := BestCharset(supported, header); got != want {
+			t.Errorf("BestCharset(%s) == %s, not %s", header, got, want)
+		}
+	}
+}
+
+func TestBestLanguage(t *testing.T) {
+	supported := []string{"en-US", "en-GB", "de", "fr-CA"}
+	cond := map[string]string{
+		"de, en;q=0.5":              "de",
+		"en":                        "en-US",
+		"en-gb, en;q=0.9":           "en-GB",
+		"en-US;q=0.2, en;q=0.5":     "en-GB",
+		"de-AT":                     "",
+		"fr":                        "fr-CA",
+		"*;q=0.5, fr;q=0.8":         "fr-CA",
+		"":                          "en-US",
+		"*, en;q=0, de;q=0, fr;q=0": "",
+	}
+	for header, want := range cond {
+		if got := BestLanguage(supported, header); got != want {
+			t.Errorf("BestLanguage(%s) == %s, not %s", header, got, want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := Offers{
+		Types:     []string{"application/json", "text/html", "text/plain;charset=us-ascii"},
+		Charsets:  []string{"utf-8", "iso-8859-1"},
+		Languages: []string{"en", "de"},
+		Encodings: []string{Gzip, Identity},
+	}
+	cond := []struct {
+		header http.Header
+		result NegotiationResult
+		err    bool
+	}{
+		{http.Header{}, NegotiationResult{"application/json", "", "en", "gzip"}, false},
+		{http.Header{"Accept": {"text/html"}, "Accept-Charset": {"iso-8859-1"}, "Accept-Language": {"de"}, "Accept-Encoding": {""}},
+			NegotiationResult{"text/html", "iso-8859-1", "de", "identity"}, false},
+		{http.Header{"Accept": {"text/plain"}, "Accept-Charset": {"utf-8"}},
+			NegotiationResult{"text/plain;charset=us-ascii", "us-ascii", "en", "gzip"}, false},
+		{http.Header{"Accept": {"text/html"}, "Accept-Charset": {"koi8-r"}},
+			NegotiationResult{"text/html", "", "en", "gzip"}, true},
+		{http.Header{"Accept": {"image/png"}, "Accept-Language": {"fr"}},
+			NegotiationResult{"", "", "", "gzip"}, true},
+	}
+	for _, c := range cond {
+		result, err := Negotiate(c.header, offers)
+		if result != c.result || (err != nil) != c.err {
+			t.Errorf("Negotiate(%v) == %v, %v; not %v", c.header, result, err, c.result)
+		}
+	}
+	result, err := Negotiate(http.Header{"Accept-Charset": {"iso-8859-1"}}, Offers{Charsets: offers.Charsets})
+	if err != nil || result.Charset != "iso-8859-1" {
+		t.Errorf("Negotiate() of charsets only == %v, %v", result, err)
+	}
+}
+
+func TestNegotiateList(t *testing.T) {
+	supported := []string{"chat", "superchat", "v2.example.com"}
+	cond := map[string]string{
+		"superchat, chat":          "superchat",
+		" , v1.example.com ,chat ": "chat",
+		"Chat":                     "",
+		"":                         "",
+		`"chat,superchat", chat`:   "chat",
+	}
+	for header, want := range cond {
+		if got := NegotiateList(supported, header); got != want {
+			t.Errorf("NegotiateList(%s) == %s, not %s", header, got, want)
+		}
+	}
+	if got := ParseList(" a, ,b ,"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("ParseList() == %v", got)
+	}
+}
+
+func TestNegotiator(t *testing.T) {
+	req := &http.Request{Header: http.Header{
+		"Accept":          {"text/html;q=0.5", "application/json"},
+		"Accept-Charset":  {"iso-8859-1, utf-8;q=0.5"},
+		"Accept-Language": {"de-AT, en;q=0.5"},
+		"Accept-Encoding": {""},
+	}}
+	n := NewNegotiator(req)
+	for i := 0; i < 2; i++ {
+		if got := n.Type("text/html", "application/json"); got != "application/json" {
+			t.Errorf("Negotiator.Type() == %s", got)
+		}
+		if got := n.Type("image/png"); got != "" {
+			t.Errorf("Negotiator.Type(image/png) == %s", got)
+		}
+		if got := n.Charset("utf-8", "iso-8859-1"); got != "iso-8859-1" {
+			t.Errorf("Negotiator.Charset() == %s", got)
+		}
+		if got := n.Language("en-GB", "de"); got != "en-GB" {
+			t.Errorf("Negotiator.Language() == %s", got)
+		}
+		if got := n.Encoding(Gzip, Identity); got != Identity {
+			t.Errorf("Negotiator.Encoding() == %s", got)
+		}
+	}
+	n = (&Policy{ClientOrder: true}).NewNegotiator(&http.Request{Header: http.Header{}})
+	if n.Type("text/html", "application/json") != "text/html" || n.Charset("utf-8") != "utf-8" ||
+		n.Language("fr") != "fr" || n.Encoding(Gzip, Identity) != Gzip {
+		t.Errorf("Negotiator of a request without headers accepts too little")
+	}
+}
@@ -0,0 +1,97 @@
+package mimeparse
+
+import (
+	"http"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A type the server can produce, with the server's own preference
+// for it. Like the 'qs' of Apache type maps it scales the quality
+// the client gives the type, so among types a client accepts equally
+// the server's favourite wins, while a strong client preference can
+// still overrule it.
+type Offer struct {
+	Type string
+	// The server's preference for the type from 0 to 1, where
+	// 0 is taken to be the default of 1.
+	Quality float
+	// Anything the caller wants to keep with the offer, such as the
+	// function that renders the type.
+	Data interface{}
+	// Where the variant of this type can be fetched directly, if it
+	// has a URI of its own, for listing in an Alternates header.
+	URI string
+}
+
+// Returns the server quality of the offer.
+func (o Offer) qs() float {
+	if o.Quality <= 0 || o.Quality > 1 {
+		return 1
+	}
+	return o.Quality
+}
+
+// Returns the offer whose type is most acceptable to the header,
+// weighing the quality the client gives each type by the quality of
+// the offer. Ties are broken as they are by BestMatch(). The boolean
+// is false if no offer is acceptable. For example with offers of
+// 'image/webp' at 1 and 'image/jpeg' at 0.8:
+//
+// BestOffer(offers, 'image/*')
+// 'image/webp'
+// BestOffer(offers, 'image/jpeg, image/*;q=0.5')
+// 'image/jpeg'
+func BestOffer(offers []Offer, header string) (Offer, bool) {
+	return defaultPolicy.BestOffer(offers, header)
+}
+
+// Like BestOffer() but applying the options of the policy.
+func (p *Policy) BestOffer(offers []Offer, header string) (Offer, bool) {
+	parsedHeader := p.ParseHeader(header)
+	best, bestQ := noMatch, 0.0
+	for i, o := range offers {
+		m, ok := p.matchSupported(i, o.Type, parsedHeader)
+		if !ok {
+			continue
+		}
+		if q := m.Quality * o.qs(); q > bestQ || (q > 0 && q == bestQ && p.winsTie(m, best)) {
+			best, bestQ = m, q
+		}
+	}
+	p.observe(best)
+	if best.index < 0 {
+		return Offer{}, false
+	}
+	return offers[best.index], true
+}
+
+// Formats offers as the value of an Alternates header in the style of
+// RFC 2295, describing each variant by its URI, its server quality
+// and its type. For example:
+//
+// {"photo.webp" 1 {type image/webp}}, {"photo.jpg" 0.8 {type image/jpeg}}
+//
+// Offers without a URI are listed with an empty one, which refers to
+// the negotiated resource itself.
+func FormatAlternates(offers []Offer) string {
+	parts := make([]string, len(offers))
+	for i, o := range offers {
+		qs := strconv.Ftoa64(math.Floor(float64(o.qs())*1000+0.5)/1000, 'f', -1)
+		parts[i] = "{" + strconv.Quote(o.URI) + " " + qs + " {type " + o.Type + "}}"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Responds with 406 Not Acceptable like NotAcceptable(), listing the
+// types of the offers in the body and describing them, with their
+// server quality, in an Alternates header.
+func NotAcceptableOffers(w http.ResponseWriter, req *http.Request, offers []Offer) {
+	types := make([]string, len(offers))
+	for i, o := range offers {
+		types[i] = o.Type
+	}
+	w.Header().Set("Alternates", FormatAlternates(offers))
+	NotAcceptable(w, req, types)
+}
@@ -0,0 +1,52 @@
+package mimeparse
+
+import (
+	"http"
+	"http/httptest"
+	"testing"
+)
+
+func TestBestOffer(t *testing.T) {
+	offers := []Offer{
+		{"image/jpeg", 0.8, "jpeg", ""},
+		{"image/webp", 1, "webp", ""},
+		{"image/png", 0, "png", ""},
+	}
+	cond := map[string]string{
+		"image/*":                        "webp",
+		"image/jpeg, image/*;q=0.5":      "jpeg",
+		"image/jpeg, image/*;q=0.85":     "webp",
+		"image/png;q=0.9, image/*;q=0.5": "png",
+		"image/jpeg, image/png":          "png",
+		"text/html":                      "",
+		"image/*;q=0":                    "",
+	}
+	for header, want := range cond {
+		o, ok := BestOffer(offers, header)
+		if got, _ := o.Data.(string); got != want || ok != (want != "") {
+			t.Errorf("BestOffer(%s) == %v, %v; not %s", header, o, ok, want)
+		}
+	}
+	p := &Policy{ClientOrder: true}
+	equal := []Offer{{Type: "text/html"}, {Type: "application/json"}}
+	if o, _ := p.BestOffer(equal, "application/json, text/html"); o.Type != "application/json" {
+		t.Errorf("ClientOrder BestOffer() == %v", o)
+	}
+}
+
+func TestAlternates(t *testing.T) {
+	offers := []Offer{
+		{Type: "image/webp", URI: "photo.webp"},
+		{Type: "image/jpeg", Quality: 0.8, URI: "photo.jpg"},
+		{Type: "image/png", Quality: 0.12345},
+	}
+	want := `{"photo.webp" 1 {type image/webp}}, {"photo.jpg" 0.8 {type image/jpeg}}, {"" 0.123 {type image/png}}`
+	if got := FormatAlternates(offers); got != want {
+		t.Errorf("FormatAlternates() == %s, not %s", got, want)
+	}
+	w := httptest.NewRecorder()
+	NotAcceptableOffers(w, &http.Request{Header: http.Header{"Accept": {"text/html"}}}, offers)
+	if w.Code != http.StatusNotAcceptable || w.Header().Get("Alternates") != want {
+		t.Errorf("NotAcceptableOffers() == %d, %v", w.Code, w.Header())
+	}
+}
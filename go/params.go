@@ -0,0 +1,104 @@
+package mimeparse
+
+// Past this many parameters a mime-type indexes them by name.
+const maxInlineParams = 8
+
+// The parameters of a mime-type in the order they first appeared.
+// Nearly every media-range has no more than a couple of parameters,
+// for which scanning a short list beats hashing and allocates less
+// than a map would, so an index is only built for the rare mime-type
+// with more than maxInlineParams of them.
+type paramList struct {
+	pairs []param
+	// position of each name in pairs, or nil
+	index map[string]int
+}
+
+type param struct {
+	key, value string
+}
+
+// Returns the value of the parameter 'key', and whether it is present.
+func (l paramList) get(key string) (value string, ok bool) {
+	if l.index != nil {
+		if i, ok := l.index[key]; ok {
+			return l.pairs[i].value, true
+		}
+		return "", false
+	}
+	for _, p := range l.pairs {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+// Like get() but returns "" for a missing parameter.
+func (l paramList) value(key string) string {
+	value, _ := l.get(key)
+	return value
+}
+
+// Sets the parameter 'key', replacing its value if already present
+// and appending it otherwise.
+func (l *paramList) set(key, value string) {
+	if l.index != nil {
+		if i, ok := l.index[key]; ok {
+			l.pairs[i].value = value
+			return
+		}
+		l.index[key] = len(l.pairs)
+	} else {
+		for i := range l.pairs {
+			if l.pairs[i].key == key {
+				l.pairs[i].value = value
+				return
+			}
+		}
+		if len(l.pairs) == maxInlineParams {
+			l.index = make(map[string]int, 2*maxInlineParams)
+			for i, p := range l.pairs {
+				l.index[p.key] = i
+			}
+			l.index[key] = len(l.pairs)
+		}
+	}
+	l.pairs = append(l.pairs, param{key, value})
+}
+
+// Returns the number of parameters.
+func (l paramList) len() int {
+	return len(l.pairs)
+}
+
+// Returns the names of the parameters in order.
+func (l paramList) keys() []string {
+	keys := make([]string, len(l.pairs))
+	for i, p := range l.pairs {
+		keys[i] = p.key
+	}
+	return keys
+}
+
+// Returns a copy that may be modified without affecting the original.
+func (l paramList) clone() paramList {
+	c := paramList{pairs: make([]param, len(l.pairs))}
+	copy(c.pairs, l.pairs)
+	if l.index != nil {
+		c.index = make(map[string]int, len(l.index))
+		for k, i := range l.index {
+			c.index[k] = i
+		}
+	}
+	return c
+}
+
+// Returns the parameters as a map.
+func (l paramList) toMap() map[string]string {
+	m := make(map[string]string, len(l.pairs))
+	for _, p := range l.pairs {
+		m[p.key] = p.value
+	}
+	return m
+}
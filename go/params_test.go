@@ -0,0 +1,49 @@
+package mimeparse
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParamList(t *testing.T) {
+	var l paramList
+	for i := 0; i < 2*maxInlineParams; i++ {
+		l.set("p"+strconv.Itoa(i), strconv.Itoa(i))
+		if (l.index != nil) != (l.len() > maxInlineParams) {
+			t.Errorf("set() with %d parameters has index %v", l.len(), l.index)
+		}
+	}
+	l.set("p3", "three")
+	l.set("p12", "twelve")
+	if l.len() != 2*maxInlineParams {
+		t.Errorf("len() == %d, not %d", l.len(), 2*maxInlineParams)
+	}
+	for k, v := range map[string]string{"p0": "0", "p3": "three", "p12": "twelve", "p15": "15"} {
+		if value, ok := l.get(k); !ok || value != v {
+			t.Errorf("get(%s) == %s, %v, not %s", k, value, ok, v)
+		}
+	}
+	if _, ok := l.get("p16"); ok {
+		t.Errorf("get(p16) found a missing parameter")
+	}
+	if keys := l.keys(); keys[0] != "p0" || keys[12] != "p12" {
+		t.Errorf("keys() == %v, not in the order set", keys)
+	}
+	c := l.clone()
+	c.set("p0", "changed")
+	c.set("new", "")
+	if l.value("p0") != "0" || l.len() != 2*maxInlineParams {
+		t.Errorf("clone() shares parameters with the original")
+	}
+
+	header := "text/html;a=1;b=2;c=3;d=4;e=5;f=6;g=7;h=8;i=9;j=10;q=0.5"
+	m, err := ParseMediaRange(header)
+	if err != nil || m.params.value("j") != "10" || m.Q() != 0.5 || len(m.ParamKeys()) != 10 {
+		t.Errorf("ParseMediaRange(%s) == %v, %v", header, m, err)
+	}
+	want := map[string]string{"level": "1", "q": "1"}
+	if m = mustParse(t, "text/html;level=1"); !reflect.DeepEqual(m.params.toMap(), want) {
+		t.Errorf("ParseMediaRange(text/html;level=1) == %v, not %v", m.params.toMap(), want)
+	}
+}
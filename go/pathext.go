@@ -0,0 +1,100 @@
+package mimeparse
+
+import (
+	"http"
+	"os"
+	"path"
+	"strings"
+)
+
+// Negotiates the type of a response from the extension of the request
+// path, such as the '.json' of '/orders/7.json', or from a query
+// parameter, as well as from the Accept header, common conventions
+// for REST APIs. The zero value
+// maps extensions with TypeByExtension() and lets the extension
+// override the header.
+type ExtensionNegotiator struct {
+	// Maps extensions, with their leading dot, to types, for example
+	// '.json' to 'application/json'. Nil uses TypeByExtension().
+	Extensions map[string]string
+	// Use the extension only when the Accept header does not name a
+	// type, that is when it is missing, empty or only has wildcards
+	// such as '*/*'. Otherwise the extension overrides the header.
+	PreferHeader bool
+	// The name of a query parameter, such as 'format', whose value is
+	// an extension without its dot, so '?format=json' works like a
+	// '.json' extension. It takes precedence over the extension.
+	FormatParam string
+	// The name of a query parameter, such as 'accept', whose value is
+	// used as the Accept header, as in '?accept=application/xml'. It
+	// takes precedence over FormatParam.
+	AcceptParam string
+	// The options used for matching, nil for the default policy.
+	Policy *Policy
+}
+
+// Returns the type an extension names, or "" if it is unknown.
+func (n *ExtensionNegotiator) typeOf(ext string) string {
+	if n.Extensions == nil {
+		return TypeByExtension(ext)
+	}
+	return n.Extensions[strings.ToLower(ext)]
+}
+
+// Returns the media-ranges the query parameters and path of the request
+// ask for, "" if they ask for nothing, along with the path without a
+// known extension. Reports whether a query parameter names an unknown
+// type, which Negotiate() only fails on when the header does not
+// decide.
+func (n *ExtensionNegotiator) override(req *http.Request) (ranges, p string, unknown bool) {
+	p = req.URL.Path
+	if ext := path.Ext(p); ext != "" {
+		if ranges = n.typeOf(ext); ranges != "" {
+			p = p[:len(p)-len(ext)]
+		}
+	}
+	query := req.URL.Query()
+	if value := query.Get(n.FormatParam); n.FormatParam != "" && value != "" {
+		ranges = n.typeOf("." + value)
+		unknown = ranges == ""
+	}
+	if value := query.Get(n.AcceptParam); n.AcceptParam != "" && value != "" {
+		ranges, unknown = value, false
+	}
+	return ranges, p, unknown
+}
+
+// Negotiates the type of the response to a request. When the path
+// ends in a known extension the path is also returned without it, so
+// '/orders/7.json' routes like '/orders/7'. An extension or query
+// parameter naming a type that is not supported gives ErrNotAcceptable
+// unless the header decides, while unknown extensions are left alone.
+// Without a usable extension or query parameter this is
+// NegotiateRequest().
+func (n *ExtensionNegotiator) Negotiate(supported []string, req *http.Request) (mime string, p string, err os.Error) {
+	policy := n.Policy
+	if policy == nil {
+		policy = defaultPolicy
+	}
+	ranges, p, unknown := n.override(req)
+	switch {
+	case (ranges == "" && !unknown) || (n.PreferHeader && namesType(policy.ParseAcceptHeader(req.Header))):
+		mime, err = policy.NegotiateRequest(supported, req)
+	case unknown:
+		return "", p, ErrNotAcceptable
+	default:
+		mime, err = policy.NegotiateType(supported, ranges)
+	}
+	return mime, p, err
+}
+
+// Reports whether some acceptable range of the header is not a
+// wildcard.
+func namesType(ranges []Mime) bool {
+	for _, r := range ranges {
+		if r.IsConcrete() && r.Q() > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package mimeparse
+
+import (
+	"http"
+	"testing"
+)
+
+func TestExtensionNegotiator(t *testing.T) {
+	supported := []string{"application/json", "text/html", "application/xml"}
+	cond := []struct {
+		n           *ExtensionNegotiator
+		url, accept string
+		mime, path  string
+		err         bool
+	}{
+		{&ExtensionNegotiator{}, "/orders/7.json", "text/html", "application/json", "/orders/7", false},
+		{&ExtensionNegotiator{}, "/orders/7.XML", "", "application/xml", "/orders/7", false},
+		{&ExtensionNegotiator{}, "/orders/7", "text/html", "text/html", "/orders/7", false},
+		{&ExtensionNegotiator{}, "/orders/7.unknown", "text/html", "text/html", "/orders/7.unknown", false},
+		{&ExtensionNegotiator{}, "/orders/7.png", "*/*", "", "/orders/7", true},
+		{&ExtensionNegotiator{PreferHeader: true}, "/orders/7.json", "text/html", "text/html", "/orders/7", false},
+		{&ExtensionNegotiator{PreferHeader: true}, "/orders/7.json", "text/*, */*;q=0.1", "application/json", "/orders/7", false},
+		{&ExtensionNegotiator{PreferHeader: true}, "/orders/7.png", "text/html", "text/html", "/orders/7", false},
+		{&ExtensionNegotiator{Extensions: map[string]string{".js": "application/json"}}, "/a.js", "", "application/json", "/a", false},
+		{&ExtensionNegotiator{Extensions: map[string]string{".js": "application/json"}}, "/a.json", "text/html", "text/html", "/a.json", false},
+	}
+	for _, c := range cond {
+		req, _ := http.NewRequest("GET", c.url, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		mime, path, err := c.n.Negotiate(supported, req)
+		if mime != c.mime || path != c.path || (err != nil) != c.err {
+			t.Errorf("Negotiate(%s, %s) == %s, %s, %v", c.url, c.accept, mime, path, err)
+		}
+	}
+}
+
+func TestQueryOverride(t *testing.T) {
+	supported := []string{"application/json", "text/html", "application/xml"}
+	n := &ExtensionNegotiator{FormatParam: "format", AcceptParam: "accept"}
+	cond := []struct {
+		url, accept, mime string
+		err               bool
+	}{
+		{"/a?format=json", "text/html", "application/json", false},
+		{"/a.html?format=xml", "", "application/xml", false},
+		{"/a?accept=application/xml,text/html%3Bq=0.5", "application/json", "application/xml", false},
+		{"/a?format=json&accept=text/*", "", "text/html", false},
+		{"/a?format=bogus", "", "", true},
+		{"/a?format=png", "", "", true},
+		{"/a?format=bogus&accept=text/html", "", "text/html", false},
+		{"/a?accept=image/png", "", "", true},
+		{"/a?format=", "text/html", "text/html", false},
+		{"/a?other=json", "text/html", "text/html", false},
+	}
+	for _, c := range cond {
+		req, _ := http.NewRequest("GET", c.url, nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		mime, path, err := n.Negotiate(supported, req)
+		if mime != c.mime || path != "/a" || (err != nil) != c.err {
+			t.Errorf("Negotiate(%s, %s) == %s, %s, %v", c.url, c.accept, mime, path, err)
+		}
+	}
+	n.PreferHeader = true
+	req, _ := http.NewRequest("GET", "/a?format=json", nil)
+	req.Header.Set("Accept", "text/html")
+	if mime, _, _ := n.Negotiate(supported, req); mime != "text/html" {
+		t.Errorf("PreferHeader Negotiate() == %s", mime)
+	}
+	// An unknown format does not matter when the header decides, and
+	// only fails when it would have been used.
+	req, _ = http.NewRequest("GET", "/a?format=bogus", nil)
+	req.Header.Set("Accept", "text/html")
+	if mime, _, err := n.Negotiate(supported, req); mime != "text/html" || err != nil {
+		t.Errorf("PreferHeader Negotiate() with an unknown format == %s, %v", mime, err)
+	}
+	req.Header.Set("Accept", "*/*")
+	if mime, _, err := n.Negotiate(supported, req); err != ErrNotAcceptable {
+		t.Errorf("PreferHeader Negotiate() with an unknown format and */* == %s, %v", mime, err)
+	}
+}
@@ -0,0 +1,136 @@
+package mimeparse
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A compiled pattern over mime-types, richer than the wildcards of
+// an Accept header. The type and the subtype may each contain '*',
+// matching any run of characters, and '{a,b}' alternatives:
+//
+// image/*
+// application/*+json
+// text/{plain,html}
+//
+// Parameters given in the pattern must be present with equal values
+// in a matching mime-type.
+type Pattern struct {
+	source   string
+	types    []string
+	subtypes []string
+	params   Mime
+}
+
+// Compiles a pattern, see Pattern.
+func CompilePattern(pattern string) (*Pattern, os.Error) {
+	parsed, err := defaultPolicy.parseMimeType(pattern, true)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.mtype == "" || parsed.subtype == "" {
+		return nil, os.NewError("Empty type or subtype in pattern")
+	}
+	p := &Pattern{source: pattern, params: parsed}
+	if p.types, err = expandBraces(parsed.mtype); err != nil {
+		return nil, err
+	}
+	if p.subtypes, err = expandBraces(parsed.subtype); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Like CompilePattern() but panics if the pattern cannot be compiled.
+// Intended for patterns in global variable initializers.
+func MustCompilePattern(pattern string) *Pattern {
+	p, err := CompilePattern(pattern)
+	if err != nil {
+		panic("mimeparse: CompilePattern(" + strconv.Quote(pattern) + "): " + err.String())
+	}
+	return p
+}
+
+// Returns the source text of the pattern.
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Reports whether the mime-type matches the pattern.
+func (p *Pattern) Match(mime Mime) bool {
+	if !globMatchAny(p.types, mime.mtype) || !globMatchAny(p.subtypes, mime.subtype) {
+		return false
+	}
+	for _, pr := range p.params.params.pairs {
+		if value, ok := mime.params.get(pr.key); !ok || !defaultPolicy.paramEqual(pr.key, value, pr.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Like Match() but parses the mime-type first. An invalid
+// mime-type never matches.
+func (p *Pattern) MatchString(mimetype string) bool {
+	parsed, err := ParseMimeType(mimetype)
+	return err == nil && p.Match(parsed)
+}
+
+// Expands '{a,b}' alternatives into every combination they describe.
+func expandBraces(s string) ([]string, os.Error) {
+	open := strings.Index(s, "{")
+	if open < 0 {
+		if strings.Index(s, "}") >= 0 || strings.Index(s, ",") >= 0 {
+			return nil, os.NewError("Unbalanced braces in pattern")
+		}
+		return []string{s}, nil
+	}
+	end := strings.Index(s[open:], "}")
+	if end < 0 {
+		return nil, os.NewError("Unbalanced braces in pattern")
+	}
+	end += open
+	if strings.Index(s[open+1:end], "{") >= 0 {
+		return nil, os.NewError("Nested braces in pattern")
+	}
+	rest, err := expandBraces(s[end+1:])
+	if err != nil {
+		return nil, err
+	}
+	expanded := []string{}
+	for _, alt := range strings.Split(s[open+1:end], ",", -1) {
+		for _, r := range rest {
+			expanded = append(expanded, s[:open]+alt+r)
+		}
+	}
+	return expanded, nil
+}
+
+func globMatchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if globMatch(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches 's' against a pattern in which '*' stands for any run
+// of characters, possibly empty.
+func globMatch(pattern, s string) bool {
+	star := strings.Index(pattern, "*")
+	if star < 0 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, pattern[:star]) {
+		return false
+	}
+	pattern, s = pattern[star+1:], s[star:]
+	for i := 0; i <= len(s); i++ {
+		if globMatch(pattern, s[i:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestPattern(t *testing.T) {
+	cond := map[string]map[string]bool{
+		"image/*": {
+			"image/png": true, "image/svg+xml": true, "text/png": false,
+		},
+		"application/*+json": {
+			"application/vnd.api+json": true, "application/+json": true,
+			"application/json": false, "application/vnd.api+xml": false,
+		},
+		"text/{plain,html}": {
+			"text/plain": true, "Text/HTML": true, "text/css": false,
+		},
+		"{text,application}/{json,xml}": {
+			"text/xml": true, "application/json": true, "image/xml": false,
+		},
+		"*/*": {
+			"video/mp4": true,
+		},
+		"text/html;level=1": {
+			"text/html;level=1": true, "text/html;level=1;q=0.5": true,
+			"text/html;level=2": false, "text/html": false,
+		},
+	}
+	for pattern, targets := range cond {
+		p, err := CompilePattern(pattern)
+		if err != nil {
+			t.Errorf("CompilePattern(%s) failed: %v", pattern, err)
+			continue
+		}
+		for target, want := range targets {
+			if got := p.MatchString(target); got != want {
+				t.Errorf("Pattern %s matching %s == %v, not %v", pattern, target, got, want)
+			}
+		}
+	}
+	for _, bad := range []string{"text/{plain", "text/plain}", "text/{a,{b}}", "text/a,b", "text", "text/"} {
+		if _, err := CompilePattern(bad); err == nil {
+			t.Errorf("CompilePattern(%s) should fail", bad)
+		}
+	}
+	if MustCompilePattern("text/*").MatchString("invalid") {
+		t.Errorf("An invalid mime-type should never match")
+	}
+}
+
+func TestMustCompilePatternPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustCompilePattern should panic on a bad pattern")
+		}
+	}()
+	MustCompilePattern("text/{plain")
+}
@@ -0,0 +1,251 @@
+package mimeparse
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A Policy selects optional behaviour for matching mime-types against
+// media-ranges. The zero value behaves exactly like the package level
+// functions, so only the options that differ need to be set:
+//
+// p := &Policy{NormalizeAliases: true}
+// p.BestMatch([]string{"application/json"}, "text/json")
+type Policy struct {
+	// Rewrite deprecated alias names, such as 'text/json', to their
+	// current names with Normalize() before comparing types.
+	NormalizeAliases bool
+
+	// Break ties between equally acceptable types in favour of the
+	// one whose media-range appears first in the Accept header,
+	// rather than the one listed first in the supported types.
+	ClientOrder bool
+
+	// Names of parameters whose values are compared without regard
+	// to case, in addition to 'charset' which always is and whose
+	// aliases are also equal, see NormalizeCharset().
+	CaseInsensitiveParams []string
+
+	// Names of parameters whose values are space separated lists, in
+	// addition to 'profile' which always is. A media-range matches
+	// when every item it lists is also listed by the mime-type, in
+	// any order, so 'profile="a"' accepts 'profile="b a"'.
+	ListParams []string
+
+	// Require a media-range naming a type and subtype to carry exactly
+	// the parameters of the supported type, so that a range without
+	// parameters cannot select a type offered with parameters. This
+	// suits APIs that version their types with a parameter. Ranges
+	// with wildcards still match regardless of parameters.
+	StrictParams bool
+
+	// Ignore all parameters when matching, comparing only types and
+	// subtypes. Takes precedence over StrictParams.
+	IgnoreParams bool
+
+	// Reject media-ranges that RFC 7231 does not allow instead of
+	// interpreting them leniently. Rejected ranges match nothing.
+	Strict bool
+
+	// Let a media-range naming a structured syntax suffix as its subtype
+	// match types with that suffix, so 'application/json' accepts
+	// 'application/vnd.api+json'. Such a match ranks below an exact
+	// subtype and above a subtype wildcard.
+	SuffixMatch bool
+
+	// Break ties between equally acceptable types in favour of types
+	// in the vendor tree, so that 'application/vnd.api+json' is chosen
+	// over 'application/json' when the client accepts both equally.
+	// Applies before ClientOrder.
+	PreferVendor bool
+
+	// How to read a 'q' that is malformed or outside 0 to 1. With
+	// Strict set such a 'q' is always an error.
+	InvalidQ InvalidQ
+
+	// Which value of a parameter that appears more than once, as in
+	// 'text/html;level=1;level=2', is kept. This only concerns the
+	// parameters before 'q': a repeated 'q', or an accept-ext named
+	// as an earlier parameter, is always dropped, see ParseMimeType().
+	// With Strict set a parameter repeated before 'q' is an error.
+	RepeatedParams RepeatedParams
+
+	// Merge media-ranges of a header that differ only in 'q', such
+	// as the duplicates some proxies add, keeping the highest 'q' at
+	// the position of the first one. See MediaRangeSet.
+	MergeDuplicates bool
+
+	// The name of a numeric parameter that versions types, such as
+	// 'version' in 'application/vnd.github+json;version=2'. A range
+	// asking for a version then accepts that version or any lower
+	// one, and among the supported types it accepts the exact version
+	// wins, followed by the highest one. Applies before PreferVendor.
+	VersionParam string
+
+	// Breaks the ties left by the other options, before falling back
+	// to the order of the supported types. See TieBreaker.
+	TieBreaker TieBreaker
+
+	// Replaces the built-in fitness of a media-range 'r' for a
+	// mime-type 'target', see FitnessAndQuality(), for domains with
+	// parameters that need their own rules. It reports whether 'r'
+	// matches 'target' at all and, if so, a score of 0 or more where
+	// the highest scoring range of a header gives the quality. The
+	// 'q' of 'r' is taken care of by the caller. Takes precedence over
+	// the other matching options.
+	Scorer func(target, r Mime) (score int, ok bool)
+
+	// The charset ParseContentType() assumes for 'text' types without
+	// a charset parameter. Empty means 'us-ascii', the default of RFC
+	// 2046, though many servers expect 'utf-8' in practice.
+	TextCharset string
+
+	// Parse mime-types with the syntax of RFC 2045 mail headers
+	// rather than that of HTTP: comments in parentheses, as in
+	// 'text/plain (plain text); charset=us-ascii', are removed, and
+	// folded lines are unfolded before CR and LF are refused.
+	MailSyntax bool
+
+	// Decode RFC 2047 encoded-words in parameter values, as in
+	// 'name="=?UTF-8?B?bmHDr3ZlLnR4dA==?="', which some mail software
+	// sends. See DecodeEncodedWords().
+	EncodedWords bool
+
+	// Limits on the inputs parsed, against hostile headers: a header
+	// or mime-type longer than MaxLength bytes, a header with more
+	// than MaxRanges elements, and a mime-type with more than
+	// MaxParams parameters are refused with ErrTooLarge. Zero selects
+	// the defaults, DefaultMaxLength and so on, and a negative value
+	// removes the limit.
+	MaxLength int
+	MaxRanges int
+	MaxParams int
+
+	// Told about the outcome of every negotiation made with the
+	// policy, for metrics such as chosen-type counts or 406 rates.
+	// A negotiation is one call of BestMatch() or any of the
+	// functions choosing a type for a caller, such as NegotiateType(),
+	// Handler() or a Matcher, and is reported once. Explain() and
+	// AllMatches() only inspect the candidates and are not reported.
+	Observer Observer
+}
+
+// Receives the outcomes of negotiations, see Policy.Observer. It is
+// called from the goroutine negotiating and may be called from many
+// at once.
+type Observer interface {
+	// Called with the chosen match, and false if no supported type
+	// was acceptable. Match.Specificity tells how the type was
+	// selected, e.g. AnyMatch for a '*/*' range.
+	OnNegotiate(m Match, ok bool)
+}
+
+// Adapts a function to the Observer interface.
+type ObserverFunc func(m Match, ok bool)
+
+func (f ObserverFunc) OnNegotiate(m Match, ok bool) {
+	f(m, ok)
+}
+
+// The handling of malformed or out of range quality values.
+type InvalidQ int
+
+const (
+	InvalidQAsOne   InvalidQ = iota // treat the range as fully acceptable
+	InvalidQAsZero                  // treat the range as not acceptable
+	InvalidQAsError                 // reject the range as a parse error
+)
+
+var invalidQNames = []string{"one", "zero", "error"}
+
+func (q InvalidQ) String() string {
+	if q < 0 || int(q) >= len(invalidQNames) {
+		return "invalid"
+	}
+	return invalidQNames[q]
+}
+
+// The limits used when a Policy leaves them at zero.
+const (
+	DefaultMaxLength = 8192
+	DefaultMaxRanges = 128
+	DefaultMaxParams = 32
+)
+
+// Returned when an input exceeds one of the limits of Policy.MaxLength,
+// Policy.MaxRanges or Policy.MaxParams.
+var ErrTooLarge = os.NewError("Input too large")
+
+// Reports whether 'n' exceeds the limit 'max', which selects the
+// default 'def' when zero and no limit when negative.
+func exceeds(n, max, def int) bool {
+	if max == 0 {
+		max = def
+	}
+	return max > 0 && n > max
+}
+
+// The policy used by the package level functions.
+var defaultPolicy = &Policy{}
+
+// Parameters whose values are case-insensitive under every policy.
+var caseInsensitiveParams = map[string]bool{"charset": true}
+
+// Parameters whose values are lists under every policy.
+var listParams = map[string]bool{"profile": true}
+
+// Reports whether the value 'value' of the parameter 'key' of a
+// media-range accepts the value 'target' of a mime-type. For list
+// parameters every item of the range must be in the target, and
+// a version may not exceed that of the range.
+func (p *Policy) paramMatch(key, value, target string) bool {
+	if p.VersionParam != "" && strings.ToLower(p.VersionParam) == key {
+		want, err1 := strconv.Atof64(value)
+		have, err2 := strconv.Atof64(target)
+		if err1 == nil && err2 == nil {
+			return have <= want
+		}
+	}
+	list := listParams[key]
+	for _, k := range p.ListParams {
+		list = list || strings.ToLower(k) == key
+	}
+	if !list {
+		return p.paramEqual(key, value, target)
+	}
+	items := strings.Fields(target)
+	for _, v := range strings.Fields(value) {
+		found := false
+		for _, t := range items {
+			found = found || p.paramEqual(key, v, t)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Compares two values of the parameter 'key'.
+func (p *Policy) paramEqual(key, a, b string) bool {
+	if a == b {
+		return true
+	}
+	if key == "charset" {
+		return NormalizeCharset(a) == NormalizeCharset(b)
+	}
+	fold := caseInsensitiveParams[key]
+	for _, k := range p.CaseInsensitiveParams {
+		fold = fold || strings.ToLower(k) == key
+	}
+	return fold && strings.ToLower(a) == strings.ToLower(b)
+}
+
+// The handling of parameters that appear more than once.
+type RepeatedParams int
+
+const (
+	LastParamWins  RepeatedParams = iota // later values replace earlier ones
+	FirstParamWins                       // later values are ignored
+)
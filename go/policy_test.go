@@ -0,0 +1,408 @@
+package mimeparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientOrder(t *testing.T) {
+	p := &Policy{ClientOrder: true}
+	supported := []string{"text/html", "application/rdf+xml", "application/json"}
+	headers := map[string]string{
+		"application/rdf+xml, text/html":                  "application/rdf+xml",
+		"text/html, application/rdf+xml":                  "text/html",
+		"application/json, text/html;q=0.9":               "application/json",
+		"text/html;q=0.5, application/json":               "application/json",
+		"*/*":                                             "text/html",
+		"application/*, text/html":                        "application/rdf+xml",
+		"image/png, application/json;q=0.2, text/*;q=0.2": "application/json",
+		"image/png":                                       "",
+	}
+	for header, result := range headers {
+		if match := p.BestMatch(supported, header); match != result {
+			t.Errorf("BestMatch(%v, %s) == %s, not %s", supported, header, match, result)
+		}
+	}
+	if BestMatch(supported, "application/rdf+xml, text/html") != "text/html" {
+		t.Errorf("Client order should only apply when the policy asks for it")
+	}
+}
+
+func TestCaseInsensitiveParams(t *testing.T) {
+	accept := "text/html;charset=UTF-8;q=0.8, text/html;charset=utf-8;level=1, text/html;version=A;q=0.5, */*;q=0.1"
+	cond := map[string]float{
+		"text/html;charset=utf-8":         0.8,
+		"text/html;Charset=Utf-8":         0.8,
+		"text/html;charset=UTF-8;level=1": 1.0,
+		"text/html;version=A":             0.5,
+		"text/html;version=a":             0.1,
+	}
+	for mime, q := range cond {
+		if got := Quality(mime, accept); got != q {
+			t.Errorf("Quality(%s) == %f, not %f", mime, got, q)
+		}
+	}
+	p := &Policy{CaseInsensitiveParams: []string{"Version"}}
+	if got := p.Quality("text/html;version=a", accept); got != 0.5 {
+		t.Errorf("Policy.Quality with case-insensitive version == %f, not 0.5", got)
+	}
+	if !ParseMediaRangeSet("text/*;charset=UTF-8").Contains(mustParse(t, "text/plain;charset=utf-8")) {
+		t.Errorf("MediaRangeSet should compare charset without regard to case")
+	}
+}
+
+func TestStrictParams(t *testing.T) {
+	p := &Policy{StrictParams: true}
+	supported := []string{"application/vnd.x;version=1", "application/vnd.x;version=2", "text/html"}
+	cond := []struct {
+		header, strict, lenient string
+	}{
+		{"application/vnd.x;version=2", "application/vnd.x;version=2", "application/vnd.x;version=2"},
+		{"application/vnd.x, application/vnd.x;version=2;q=0.5", "application/vnd.x;version=2", "application/vnd.x;version=1"},
+		{"application/vnd.x", "", "application/vnd.x;version=1"},
+		{"application/*", "application/vnd.x;version=1", "application/vnd.x;version=1"},
+		{"application/vnd.x;version=3, text/html;q=0.1", "text/html", "text/html"},
+		{"*/*;q=0.5, application/vnd.x;version=2", "application/vnd.x;version=2", "application/vnd.x;version=2"},
+	}
+	for _, c := range cond {
+		if got := p.BestMatch(supported, c.header); got != c.strict {
+			t.Errorf("Strict BestMatch(%s) == %s, not %s", c.header, got, c.strict)
+		}
+		if got := BestMatch(supported, c.header); got != c.lenient {
+			t.Errorf("BestMatch(%s) == %s, not %s", c.header, got, c.lenient)
+		}
+	}
+}
+
+func TestIgnoreParams(t *testing.T) {
+	p := &Policy{IgnoreParams: true, StrictParams: true}
+	accept := "text/html;level=1;q=0.8, text/html;level=2;q=0.4, text/*;q=0.2"
+	cond := map[string]float{
+		"text/html":         0.8,
+		"text/html;level=2": 0.8,
+		"text/plain":        0.2,
+	}
+	for mime, q := range cond {
+		if got := p.Quality(mime, accept); got != q {
+			t.Errorf("Quality(%s) ignoring parameters == %f, not %f", mime, got, q)
+		}
+	}
+	if fitness, _ := p.FitnessAndQuality("text/html;level=2", ParseHeader(accept)); fitness != 111 {
+		t.Errorf("Parameters should not add fitness, got %d", fitness)
+	}
+	if got := Quality("text/html", accept); got != 0.2 {
+		t.Errorf("Parameters should only be ignored when the policy asks for it, got %f", got)
+	}
+}
+
+func TestWildcardTypeConcreteSubtype(t *testing.T) {
+	m, err := ParseMediaRange("*/xml;q=0.5")
+	if err != nil || m.mtype != "*" || m.subtype != "*" || m.Q() != 0.5 {
+		t.Errorf("Lenient ParseMediaRange(*/xml) == %v, %v", m, err)
+	}
+	strict := &Policy{Strict: true}
+	if _, err := strict.ParseMediaRange("*/xml"); err == nil {
+		t.Errorf("Strict ParseMediaRange(*/xml) should fail")
+	}
+	if _, err := strict.ParseMediaRange("*/*"); err != nil {
+		t.Errorf("Strict ParseMediaRange(*/*) failed: %v", err)
+	}
+	supported := []string{"application/json", "text/html"}
+	header := "*/xml;q=0.5, text/html;q=0.1"
+	if got := BestMatch(supported, header); got != "application/json" {
+		t.Errorf("Lenient BestMatch(%s) == %s", header, got)
+	}
+	if got := strict.BestMatch(supported, header); got != "text/html" {
+		t.Errorf("Strict BestMatch(%s) == %s", header, got)
+	}
+}
+
+func TestSuffixMatch(t *testing.T) {
+	p := &Policy{SuffixMatch: true}
+	supported := []string{"application/vnd.example+json", "application/atom+xml", "text/html"}
+	cond := []struct {
+		header, suffix, plain string
+	}{
+		{"application/json", "application/vnd.example+json", ""},
+		{"application/xml", "application/atom+xml", ""},
+		{"text/json, text/html;q=0.1", "text/html", "text/html"},
+		{"application/json;q=0.2, application/*;q=0.5", "application/atom+xml", "application/vnd.example+json"},
+		{"application/json;q=0.5, application/*;q=0.2", "application/vnd.example+json", "application/vnd.example+json"},
+		{"application/json, application/vnd.example+json;q=0.3", "application/vnd.example+json", "application/vnd.example+json"},
+	}
+	for _, c := range cond {
+		if got := p.BestMatch(supported, c.header); got != c.suffix {
+			t.Errorf("BestMatch(%s) with suffix matching == %s, not %s", c.header, got, c.suffix)
+		}
+		if got := BestMatch(supported, c.header); got != c.plain {
+			t.Errorf("BestMatch(%s) == %s, not %s", c.header, got, c.plain)
+		}
+	}
+	if q := p.Quality("application/vnd.example+json", "application/json;q=0.5, application/*;q=0.2"); q != 0.5 {
+		t.Errorf("A suffix match should outrank a subtype wildcard, got %f", q)
+	}
+}
+
+func TestPreferVendor(t *testing.T) {
+	p := &Policy{PreferVendor: true}
+	supported := []string{"application/json", "application/vnd.api+json", "text/html"}
+	cond := []struct {
+		header, vendor, plain string
+	}{
+		{"application/vnd.api+json, application/json", "application/vnd.api+json", "application/json"},
+		{"*/*", "application/vnd.api+json", "application/json"},
+		{"application/json, application/vnd.api+json;q=0.9", "application/json", "application/json"},
+		{"text/html", "text/html", "text/html"},
+	}
+	for _, c := range cond {
+		if got := p.BestMatch(supported, c.header); got != c.vendor {
+			t.Errorf("BestMatch(%s) preferring vendor types == %s, not %s", c.header, got, c.vendor)
+		}
+		if got := BestMatch(supported, c.header); got != c.plain {
+			t.Errorf("BestMatch(%s) == %s, not %s", c.header, got, c.plain)
+		}
+	}
+	all := matchTypes(p.AllMatches(supported, "*/*"))
+	if all[0] != "application/vnd.api+json" {
+		t.Errorf("AllMatches preferring vendor types == %v", all)
+	}
+	both := &Policy{PreferVendor: true, ClientOrder: true}
+	if got := both.BestMatch(supported, "text/html, application/json"); got != "text/html" {
+		t.Errorf("Client order should break remaining ties, got %s", got)
+	}
+}
+
+func TestQValueDecimals(t *testing.T) {
+	cond := map[string]float64{
+		"text/html;q=0.1234": 0.123,
+		"text/html;q=0.9996": 1,
+		"text/html;q=.5":     0.5,
+		"text/html;q=0.125":  0.125,
+	}
+	strict := &Policy{Strict: true}
+	for in, q := range cond {
+		if m, err := ParseMediaRange(in); err != nil || m.Q() != q {
+			t.Errorf("ParseMediaRange(%s).Q() == %v, not %v", in, m.Q(), q)
+		}
+		_, err := strict.ParseMediaRange(in)
+		if valid := in == "text/html;q=0.125"; (err == nil) != valid {
+			t.Errorf("Strict ParseMediaRange(%s) error == %v", in, err)
+		}
+	}
+}
+
+func TestInvalidQ(t *testing.T) {
+	supported := []string{"application/json", "text/html"}
+	header := "application/json;q=2, text/html;q=0.5"
+	cond := []struct {
+		policy *Policy
+		q      float64
+		err    bool
+		best   string
+	}{
+		{&Policy{}, 1, false, "application/json"},
+		{&Policy{InvalidQ: InvalidQAsOne}, 1, false, "application/json"},
+		{&Policy{InvalidQ: InvalidQAsZero}, 0, false, "text/html"},
+		{&Policy{InvalidQ: InvalidQAsError}, 0, true, "text/html"},
+		{&Policy{Strict: true}, 0, true, "text/html"},
+	}
+	for _, c := range cond {
+		for _, q := range []string{"2", "-1", "x", ""} {
+			m, err := c.policy.ParseMediaRange("text/html;q=" + q)
+			if (err != nil) != c.err || m.Q() != c.q {
+				t.Errorf("ParseMediaRange(q=%s) with %v == %v, %v", q, c.policy.InvalidQ, m.Q(), err)
+			}
+		}
+		if got := c.policy.BestMatch(supported, header); got != c.best {
+			t.Errorf("BestMatch(%s) with %v == %s, not %s", header, c.policy.InvalidQ, got, c.best)
+		}
+	}
+}
+
+func TestRepeatedParams(t *testing.T) {
+	cond := []struct {
+		policy *Policy
+		level  string
+		q      float64
+		err    bool
+	}{
+		{&Policy{}, "2", 0.5, false},
+		{&Policy{RepeatedParams: LastParamWins}, "2", 0.5, false},
+		{&Policy{RepeatedParams: FirstParamWins}, "1", 0.5, false},
+		{&Policy{Strict: true}, "", 0, true},
+	}
+	for _, c := range cond {
+		m, err := c.policy.ParseMediaRange("text/html;level=1;LEVEL=2;q=0.5;q=0.9")
+		if (err != nil) != c.err || m.params.value("level") != c.level || m.Q() != c.q {
+			t.Errorf("ParseMediaRange() with %v == %v, %v", c.policy.RepeatedParams, m, err)
+		}
+		// After 'q' the second 'level' is an accept-ext, not a
+		// repeat, and is dropped whatever the policy.
+		m, err = c.policy.ParseMediaRange("text/html;level=1;q=0.5;level=2")
+		if err != nil || m.params.value("level") != "1" || m.Q() != 0.5 || !m.isOrdinary("level") {
+			t.Errorf("ParseMediaRange() with %v and level after q == %v, %v", c.policy.RepeatedParams, m, err)
+		}
+	}
+	if m, _ := ParseMimeType("text/html;level=1;level=2"); m.String() != "text/html;level=2" {
+		t.Errorf("ParseMimeType(text/html;level=1;level=2) == %s", m)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	header := "text/html;q=0.2, application/json;q=0.5, Text/HTML;q=0.8, application/json;q=0.1"
+	p := &Policy{MergeDuplicates: true}
+	parsed := p.ParseHeader(header)
+	if got := NewMediaRangeSet(parsed...).String(); len(parsed) != 2 || got != "text/html;q=0.8, application/json;q=0.5" {
+		t.Errorf("ParseHeader(%s) == %s", header, got)
+	}
+	if got := len(ParseHeader(header)); got != 4 {
+		t.Errorf("ParseHeader(%s) has %d ranges, not 4", header, got)
+	}
+	supported := []string{"application/json", "text/html"}
+	if got := p.BestMatch(supported, header); got != "text/html" {
+		t.Errorf("BestMatch(%s) == %s", header, got)
+	}
+}
+
+func TestProfileParam(t *testing.T) {
+	compacted := `application/ld+json;profile="http://www.w3.org/ns/json-ld#compacted"`
+	expanded := `application/ld+json;profile="http://www.w3.org/ns/json-ld#expanded http://example.com/p"`
+	supported := []string{compacted, expanded}
+	bestMatch(t, supported, map[string]string{
+		`application/ld+json;profile="http://www.w3.org/ns/json-ld#expanded"`:                       expanded,
+		`application/ld+json;profile="http://example.com/p  http://www.w3.org/ns/json-ld#expanded"`: expanded,
+		`application/ld+json;profile="http://www.w3.org/ns/json-ld#compacted"`:                      compacted,
+		`application/ld+json;profile="http://www.w3.org/ns/json-ld#flattened"`:                      "",
+		`application/ld+json`: compacted,
+	})
+	p := &Policy{ListParams: []string{"Codecs"}}
+	supported = []string{`video/mp4;codecs="avc1.4d002a mp4a.40.2"`}
+	if got := p.BestMatch(supported, `video/mp4;codecs="mp4a.40.2"`); got != supported[0] {
+		t.Errorf("ListParams BestMatch() == %s", got)
+	}
+	if got := BestMatch(supported, `video/mp4;codecs="mp4a.40.2"`); got != "" {
+		t.Errorf("BestMatch() == %s", got)
+	}
+}
+
+func TestVersionParam(t *testing.T) {
+	p := &Policy{VersionParam: "Version"}
+	supported := []string{
+		"application/vnd.example+json;version=1",
+		"application/vnd.example+json;version=3",
+		"application/vnd.example+json;version=2",
+		"text/html",
+	}
+	cond := map[string]string{
+		"application/vnd.example+json;version=2":            supported[2],
+		"application/vnd.example+json;version=5":            supported[1],
+		"application/vnd.example+json;version=2.5":          supported[2],
+		"application/vnd.example+json;version=0":            "",
+		"application/vnd.example+json":                      supported[0],
+		"application/vnd.example+json;version=1, text/html": supported[0],
+	}
+	for header, want := range cond {
+		if got := p.BestMatch(supported, header); got != want {
+			t.Errorf("Version BestMatch(%s) == %s, not %s", header, got, want)
+		}
+	}
+	if got := BestMatch(supported, "application/vnd.example+json;version=5"); got != "" {
+		t.Errorf("BestMatch() without VersionParam == %s", got)
+	}
+	all := matchTypes(p.AllMatches(supported, "application/vnd.example+json;version=2"))
+	if !reflect.DeepEqual(all, []string{supported[2], supported[0]}) {
+		t.Errorf("Version AllMatches() == %v", all)
+	}
+}
+
+func TestScorer(t *testing.T) {
+	// Matches video types by the number of requested codecs they offer,
+	// needing at least one, instead of the usual parameter rules.
+	p := &Policy{Scorer: func(target, r Mime) (int, bool) {
+		if r.Type() != "*" && (r.Type() != target.Type() || (r.Subtype() != "*" && r.Subtype() != target.Subtype())) {
+			return 0, false
+		}
+		score := 0
+		for _, c := range strings.Split(r.params.value("codecs"), ",", -1) {
+			if c != "" && strings.Contains(target.params.value("codecs"), c) {
+				score++
+			}
+		}
+		return score, score > 0 || r.params.value("codecs") == ""
+	}}
+	supported := []string{`video/mp4;codecs="avc1"`, `video/mp4;codecs="avc1,mp4a"`, `video/webm;codecs="vp9"`}
+	cond := map[string]string{
+		`video/mp4;codecs="mp4a";q=0.9, video/*;q=0.1`: supported[1],
+		`video/mp4;codecs="hevc"`:                      "",
+		`video/mp4;codecs="hevc,avc1"`:                 supported[0],
+		`video/webm, video/mp4;q=0.5`:                  supported[2],
+		`audio/*`:                                      "",
+	}
+	for header, want := range cond {
+		if got := p.BestMatch(supported, header); got != want {
+			t.Errorf("Scorer BestMatch(%s) == %s, not %s", header, got, want)
+		}
+	}
+	if q := p.Quality(supported[0], `video/mp4;codecs="avc1";q=0.7, video/*;q=0.2`); q != 0.7 {
+		t.Errorf("Scorer Quality() == %v", q)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	chosen := map[string]int{}
+	failed, wildcard := 0, 0
+	p := &Policy{Observer: ObserverFunc(func(m Match, ok bool) {
+		switch {
+		case !ok:
+			failed++
+		case m.Specificity == AnyMatch:
+			wildcard++
+		}
+		chosen[m.Type]++
+	})}
+	supported := []string{"application/json", "text/html"}
+	for _, header := range []string{"text/html", "*/*", "image/png", "application/json", "*/*"} {
+		p.BestMatch(supported, header)
+	}
+	p.BestOffer([]Offer{{Type: "text/html"}}, "text/*")
+	if failed != 1 || wildcard != 2 || chosen["application/json"] != 3 || chosen["text/html"] != 2 {
+		t.Errorf("Observer saw %d failures, %d wildcards and %v", failed, wildcard, chosen)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	manyRanges := strings.Repeat("text/plain;q=0.1, ", DefaultMaxRanges) + "text/html"
+	manyParams := "text/html" + strings.Repeat(";a=b", DefaultMaxParams+1)
+	long := "text/html;a=" + strings.Repeat("x", DefaultMaxLength)
+	for _, header := range []string{manyRanges, long} {
+		if parsed := ParseHeader(header); len(parsed) != 0 {
+			t.Errorf("ParseHeader() of %d bytes gave %d ranges", len(header), len(parsed))
+		}
+		if _, err := NegotiateType([]string{"text/html"}, header); err != ErrTooLarge {
+			t.Errorf("NegotiateType() of %d bytes error == %v", len(header), err)
+		}
+	}
+	for _, mimetype := range []string{manyParams, long} {
+		if _, err := ParseMimeType(mimetype); err != ErrTooLarge {
+			t.Errorf("ParseMimeType() of %d bytes error == %v", len(mimetype), err)
+		}
+	}
+	if got := BestCharset([]string{"utf-8"}, strings.Repeat("latin1, ", DefaultMaxRanges)+"utf-8"); got != "" {
+		t.Errorf("BestCharset() with too many elements == %s", got)
+	}
+	unlimited := &Policy{MaxLength: -1, MaxRanges: -1, MaxParams: -1}
+	if got := unlimited.BestMatch([]string{"text/html"}, manyRanges+", "+long); got != "text/html" {
+		t.Errorf("BestMatch() without limits == %s", got)
+	}
+	if _, err := unlimited.ParseMimeType(manyParams); err != nil {
+		t.Errorf("ParseMimeType() without limits failed: %v", err)
+	}
+	small := &Policy{MaxRanges: 1, MaxParams: 1}
+	if _, err := small.NegotiateType([]string{"text/html"}, "text/html, text/plain"); err != ErrTooLarge {
+		t.Errorf("NegotiateType() with MaxRanges 1 error == %v", err)
+	}
+	if _, err := small.ParseMediaRange("text/html;level=1;q=0.5"); err != ErrTooLarge {
+		t.Errorf("ParseMediaRange() with MaxParams 1 error == %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package mimeparse
+
+// Lists of ranges no longer in use, kept for the next header to be
+// parsed. Negotiation parses the Accept header of every request only
+// to pick a type, so reusing the lists spares a busy server most of
+// that garbage. A buffered channel serves as a free list that is safe
+// for concurrent use; when it is full, lists are left to the
+// collector.
+var rangePool = make(chan []Mime, 32)
+
+// Lists longer than this are not kept, so that one large header does
+// not hold on to memory.
+const maxPooledRanges = 32
+
+// Returns a list of n zero ranges, reusing a recycled one if possible.
+func getRanges(n int) []Mime {
+	select {
+	case r := <-rangePool:
+		if cap(r) >= n {
+			return r[:n]
+		}
+	default:
+	}
+	return make([]Mime, n)
+}
+
+// Returns a list of ranges obtained from ParseHeader() for reuse by
+// later calls. Servers parsing many headers may call it once they are
+// done with the ranges to cut down on garbage; the list, though not
+// the ranges copied out of it, must not be used afterwards.
+func Recycle(parsed []Mime) {
+	if cap(parsed) == 0 || cap(parsed) > maxPooledRanges {
+		return
+	}
+	parsed = parsed[:cap(parsed)]
+	for i := range parsed {
+		parsed[i] = Mime{}
+	}
+	select {
+	case rangePool <- parsed[:0]:
+	default:
+	}
+}
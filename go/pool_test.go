@@ -0,0 +1,24 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestRecycle(t *testing.T) {
+	parsed := ParseHeader("text/html;level=1, application/json")
+	Recycle(parsed)
+	again := getRanges(1)
+	if len(again) != 1 || again[0].params.len() != 0 || again[0].mtype != "" {
+		t.Errorf("getRanges(1) == %v, not a cleared range", again)
+	}
+	Recycle(again)
+
+	header := "text/*;q=0.5, application/*;q=0.8"
+	best, _ := BestMatchRange([]string{"application/json"}, header)
+	BestMatch([]string{"text/html"}, "text/html;level=2, */*;q=0.1")
+	if best.Range.Q() != 0.8 || best.Range.Subtype() != "*" {
+		t.Errorf("BestMatchRange([application/json], %s) == %v after reuse", header, best.Range)
+	}
+	Recycle(nil)
+	Recycle(make([]Mime, maxPooledRanges+1))
+}
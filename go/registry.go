@@ -0,0 +1,97 @@
+package mimeparse
+
+import (
+	"strings"
+)
+
+// The registry tables in ianatypes.go are generated by ianagen from
+// the IANA CSVs; refresh them with 'make iana'.
+
+// The status of a media type in the IANA registry.
+type RegistryStatus int
+
+const (
+	Unknown     RegistryStatus = iota // not in the registry
+	Provisional                       // in the provisional standard registry
+	Registered                        // in the media type registry
+)
+
+var registryStatusNames = []string{"unknown", "provisional", "registered"}
+
+func (s RegistryStatus) String() string {
+	if s < 0 || int(s) >= len(registryStatusNames) {
+		return "invalid"
+	}
+	return registryStatusNames[s]
+}
+
+// The status of each type in the registry tables, see newRegistry().
+var registry = newRegistry(registeredTypes, provisionalTypes)
+
+// Maps the listed types to their status. A type listed in both tables
+// has completed its registration and is Registered.
+func newRegistry(registered, provisional []string) map[string]RegistryStatus {
+	r := make(map[string]RegistryStatus, len(registered)+len(provisional))
+	for _, t := range provisional {
+		r[t] = Provisional
+	}
+	for _, t := range registered {
+		r[t] = Registered
+	}
+	return r
+}
+
+// Looks up the status of a mime-type in a registry from newRegistry().
+func registryStatus(r map[string]RegistryStatus, mime Mime) RegistryStatus {
+	return r[mime.mtype+"/"+mime.subtype]
+}
+
+// Reports whether the type and subtype of a mime-type are registered
+// with IANA, provisionally registered, or unknown. Parameters are
+// ignored. Useful for linting API specifications and the Content-Type
+// headers a server sends.
+//
+// ValidateRegistered(ParseMimeType("application/vnd.api+json"))
+// Registered
+func ValidateRegistered(mime Mime) RegistryStatus {
+	return registryStatus(registry, mime)
+}
+
+// The structured syntax suffixes registered with IANA, mapped to the
+// syntax they name, see RFC 6839. Built from registeredSuffixes in
+// ianatypes.go.
+var structuredSuffixes = suffixMap(registeredSuffixes)
+
+func suffixMap(pairs [][2]string) map[string]string {
+	m := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		m[p[0]] = p[1]
+	}
+	return m
+}
+
+// Looks up a structured syntax suffix, given with or without its
+// '+', in the IANA registry, returning the name of the syntax it
+// stands for. Tools can use it to pick a decoder for a type by its
+// suffix, or to warn about types with unregistered suffixes.
+//
+// LookupSuffix('+json')
+// 'JavaScript Object Notation (JSON)', true
+func LookupSuffix(suffix string) (name string, ok bool) {
+	if strings.HasPrefix(suffix, "+") {
+		suffix = suffix[1:]
+	}
+	name, ok = structuredSuffixes[strings.ToLower(suffix)]
+	return
+}
+
+// Reports whether the final structured syntax suffix of a mime-type,
+// see Mime.Suffix(), is registered with IANA. Types without a suffix
+// have none to register and are reported as well.
+func HasRegisteredSuffix(mime Mime) bool {
+	if !strings.Contains(mime.subtype, "+") {
+		return true
+	}
+	_, ok := LookupSuffix(mime.Suffix())
+	return ok
+}
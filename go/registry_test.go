@@ -0,0 +1,97 @@
+package mimeparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRegistered(t *testing.T) {
+	cond := map[string]RegistryStatus{
+		"application/json":                  Registered,
+		"Application/JSON; charset=utf-8":   Registered,
+		"application/vnd.api+json":          Registered,
+		"text/html":                         Registered,
+		"image/png":                         Registered,
+		"application/x-foo":                 Unknown,
+		"text/*":                            Unknown,
+		"application/definitely-not-a-type": Unknown,
+	}
+	for mime, status := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := ValidateRegistered(m); got != status {
+			t.Errorf("ValidateRegistered(%s) == %s, not %s", mime, got, status)
+		}
+	}
+}
+
+func TestValidateProvisional(t *testing.T) {
+	r := newRegistry([]string{"application/json", "application/example-done"},
+		[]string{"application/example-provisional", "application/example-done"})
+	cond := map[string]RegistryStatus{
+		"application/json":                    Registered,
+		"Application/Example-Provisional;a=b": Provisional,
+		"application/example-done":            Registered,
+		"application/example-unknown":         Unknown,
+	}
+	for mime, status := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := registryStatus(r, m); got != status {
+			t.Errorf("registryStatus(%s) == %s, not %s", mime, got, status)
+		}
+	}
+	registered := newRegistry(registeredTypes, nil)
+	for _, s := range provisionalTypes {
+		m, _ := ParseMimeType(s)
+		want := Provisional
+		if registryStatus(registered, m) == Registered {
+			want = Registered
+		}
+		if got := ValidateRegistered(m); got != want {
+			t.Errorf("ValidateRegistered(%s) == %s, not %s", s, got, want)
+		}
+	}
+	if len(provisionalTypes) == 0 {
+		t.Log("provisionalTypes is empty, run 'make iana' to fetch it")
+	}
+}
+
+func TestRegistryTables(t *testing.T) {
+	for _, list := range [][]string{registeredTypes, provisionalTypes} {
+		for _, s := range list {
+			if m, err := ParseMediaType(s); err != nil || m.Type()+"/"+m.Subtype() != s {
+				t.Errorf("Registry entry %s is not a lower-case media type", s)
+			}
+		}
+	}
+}
+
+func TestLookupSuffix(t *testing.T) {
+	for _, suffix := range []string{"json", "+json", "+XML", "cbor-seq", "zip", "der"} {
+		if name, ok := LookupSuffix(suffix); !ok || name == "" {
+			t.Errorf("LookupSuffix(%s) == %s, %v", suffix, name, ok)
+		}
+	}
+	for _, suffix := range []string{"", "+", "protobuf", "html"} {
+		if _, ok := LookupSuffix(suffix); ok {
+			t.Errorf("LookupSuffix(%s) should fail", suffix)
+		}
+	}
+	cond := map[string]bool{
+		"application/vnd.api+json":     true,
+		"application/vnd.foo+ber+der":  true,
+		"application/json":             true,
+		"application/vnd.foo+protobuf": false,
+		"application/vnd.foo+":         false,
+	}
+	for mime, want := range cond {
+		m, _ := ParseMimeType(mime)
+		if got := HasRegisteredSuffix(m); got != want {
+			t.Errorf("HasRegisteredSuffix(%s) == %v, not %v", mime, got, want)
+		}
+	}
+	for suffix := range structuredSuffixes {
+		if suffix != strings.ToLower(suffix) || !isToken(suffix) {
+			t.Errorf("Bad suffix entry %s", suffix)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package mimeparse
+
+import (
+	"bytes"
+	"fmt"
+	"http"
+	"io"
+	"json"
+	"os"
+	"sync"
+	"xml"
+)
+
+// Serializes values as one media type.
+type Encoder interface {
+	// The type written by Encode(), used for negotiation and as
+	// the Content-Type of the response.
+	ContentType() Mime
+	Encode(w io.Writer, v interface{}) os.Error
+}
+
+type encoderFunc struct {
+	mime   Mime
+	encode func(io.Writer, interface{}) os.Error
+}
+
+func (e encoderFunc) ContentType() Mime { return e.mime }
+
+func (e encoderFunc) Encode(w io.Writer, v interface{}) os.Error { return e.encode(w, v) }
+
+// Returns an Encoder for the type that calls 'encode', which makes it
+// easy to plug in a serialization such as MessagePack. Panics if the
+// type cannot be parsed.
+func EncoderFunc(mimetype string, encode func(w io.Writer, v interface{}) os.Error) Encoder {
+	mime, err := ParseMimeType(mimetype)
+	if err != nil {
+		panic("mimeparse: EncoderFunc(" + mimetype + "): " + err.String())
+	}
+	return encoderFunc{mime, encode}
+}
+
+// Encodes values as JSON.
+var JSONEncoder = EncoderFunc(ApplicationJSON, func(w io.Writer, v interface{}) os.Error {
+	return json.NewEncoder(w).Encode(v)
+})
+
+// Encodes values as XML.
+var XMLEncoder = EncoderFunc(ApplicationXML, func(w io.Writer, v interface{}) os.Error {
+	return xml.Marshal(w, v)
+})
+
+// Encodes values as UTF-8 text in the format of fmt.Print().
+var TextEncoder = EncoderFunc(TextPlainUTF8, func(w io.Writer, v interface{}) os.Error {
+	_, err := fmt.Fprint(w, v)
+	return err
+})
+
+// The encoders used by Render(), in order of preference.
+var encoders = struct {
+	sync.Mutex
+	list []Encoder
+}{list: []Encoder{JSONEncoder, XMLEncoder, TextEncoder}}
+
+// Makes an encoder available to Render(). It replaces an encoder
+// registered for the same type and parameters, otherwise it is added
+// after the others, so the built-in JSON, XML and text encoders keep
+// their preference.
+func RegisterEncoder(e Encoder) {
+	encoders.Lock()
+	defer encoders.Unlock()
+	key := rangeKey(e.ContentType())
+	list := make([]Encoder, 0, len(encoders.list)+1)
+	replaced := false
+	for _, existing := range encoders.list {
+		if rangeKey(existing.ContentType()) == key {
+			existing, replaced = e, true
+		}
+		list = append(list, existing)
+	}
+	if !replaced {
+		list = append(list, e)
+	}
+	encoders.list = list
+}
+
+// Serializes 'v' as a response to the request, with the registered
+// encoder whose type is most acceptable to the request's Accept
+// header. Sets the Content-Type and adds 'Accept' to the Vary header.
+// Encodes into a buffer first, so that on an error nothing has been
+// written and the caller can still respond with an error status. If
+// no encoder is acceptable it responds with NotAcceptable() and
+// returns ErrNotAcceptable.
+func Render(w http.ResponseWriter, req *http.Request, v interface{}) os.Error {
+	return defaultPolicy.Render(w, req, v)
+}
+
+// Like Render() but applying the options of the policy.
+func (p *Policy) Render(w http.ResponseWriter, req *http.Request, v interface{}) os.Error {
+	e, types := p.chooseEncoder(req)
+	if e == nil {
+		NotAcceptable(w, req, types)
+		return ErrNotAcceptable
+	}
+	body := new(bytes.Buffer)
+	if err := e.Encode(body, v); err != nil {
+		return err
+	}
+	addVary(w.Header(), "Accept")
+	w.Header().Set("Content-Type", e.ContentType().String())
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// Returns the registered encoder most acceptable to the request, or
+// nil if none is, along with the types of all the encoders.
+func (p *Policy) chooseEncoder(req *http.Request) (Encoder, []string) {
+	encoders.Lock()
+	list := encoders.list
+	encoders.Unlock()
+	types := make([]string, len(list))
+	for i, e := range list {
+		types[i] = e.ContentType().String()
+	}
+	if i := p.BestMatchIndex(types, requestAccept(req.Header)); i >= 0 {
+		return list[i], types
+	}
+	return nil, types
+}
+
+// A ResponseWriter that holds on to a value until the status is
+// written, and then renders it in the type negotiated for the
+// request as Render() does. It lets handlers that produce a value
+// leave its serialization to the writer:
+//
+// w.(*NegotiatingWriter).SetValue(v)
+// w.WriteHeader(http.StatusCreated)
+//
+// Without a value it passes everything through unchanged.
+type NegotiatingWriter struct {
+	http.ResponseWriter
+	policy      *Policy
+	req         *http.Request
+	value       interface{}
+	hasValue    bool
+	wroteHeader bool
+	err         os.Error
+}
+
+// Wraps the writer of a response to the request.
+func NewNegotiatingWriter(w http.ResponseWriter, req *http.Request) *NegotiatingWriter {
+	return defaultPolicy.NewNegotiatingWriter(w, req)
+}
+
+// Like NewNegotiatingWriter() but applying the options of the policy.
+func (p *Policy) NewNegotiatingWriter(w http.ResponseWriter, req *http.Request) *NegotiatingWriter {
+	return &NegotiatingWriter{ResponseWriter: w, policy: p, req: req}
+}
+
+// Sets the value to render when the status is written.
+func (w *NegotiatingWriter) SetValue(v interface{}) {
+	w.value, w.hasValue = v, true
+}
+
+// Writes the status and, if a value was set, renders it. The status
+// becomes 406 Not Acceptable if no encoder is acceptable to the
+// request, and 500 Internal Server Error if encoding fails.
+func (w *NegotiatingWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if !w.hasValue {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	e, types := w.policy.chooseEncoder(w.req)
+	if e == nil {
+		w.err = ErrNotAcceptable
+		NotAcceptable(w.ResponseWriter, w.req, types)
+		return
+	}
+	body := new(bytes.Buffer)
+	if w.err = e.Encode(body, w.value); w.err != nil {
+		http.Error(w.ResponseWriter, w.err.String(), http.StatusInternalServerError)
+		return
+	}
+	addVary(w.Header(), "Accept")
+	w.Header().Set("Content-Type", e.ContentType().String())
+	w.ResponseWriter.WriteHeader(code)
+	w.ResponseWriter.Write(body.Bytes())
+}
+
+// Writes to the response, first writing a status of 200 OK if no
+// status has been written.
+func (w *NegotiatingWriter) Write(b []byte) (int, os.Error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Returns the error that kept the value from being rendered, if any.
+func (w *NegotiatingWriter) Err() os.Error {
+	return w.err
+}
+
+// Wraps a handler so that it is given a *NegotiatingWriter, and a
+// value it sets without writing a status is rendered with 200 OK.
+func Negotiating(h http.Handler) http.Handler {
+	return defaultPolicy.Negotiating(h)
+}
+
+// Like Negotiating() but applying the options of the policy.
+func (p *Policy) Negotiating(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		nw := p.NewNegotiatingWriter(w, req)
+		h.ServeHTTP(nw, req)
+		if nw.hasValue && !nw.wroteHeader {
+			nw.WriteHeader(http.StatusOK)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package mimeparse
+
+import (
+	"http"
+	"http/httptest"
+	"io"
+	"os"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+}
+
+func (p point) String() string { return "(1, 2)" }
+
+func TestRender(t *testing.T) {
+	cond := []struct {
+		accept, contentType, body string
+	}{
+		{"", "application/json", `{"X":1,"Y":2}` + "\n"},
+		{"application/xml, application/json;q=0.5", "application/xml", "<point><X>1</X><Y>2</Y></point>"},
+		{"text/*", "text/plain;charset=utf-8", "(1, 2)"},
+	}
+	for _, c := range cond {
+		req := &http.Request{Header: http.Header{}}
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+		if err := Render(w, req, point{1, 2}); err != nil {
+			t.Errorf("Render(%s) failed: %v", c.accept, err)
+		}
+		if w.Header().Get("Content-Type") != c.contentType || w.Header().Get("Vary") != "Accept" || w.Body.String() != c.body {
+			t.Errorf("Render(%s) == %v, %s", c.accept, w.Header(), w.Body.String())
+		}
+	}
+	req := &http.Request{Header: http.Header{"Accept": {"image/png"}}}
+	w := httptest.NewRecorder()
+	if err := Render(w, req, point{1, 2}); err != ErrNotAcceptable || w.Code != http.StatusNotAcceptable {
+		t.Errorf("Render(image/png) == %v, %d", err, w.Code)
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	defer func(list []Encoder) { encoders.list = list }(encoders.list)
+	RegisterEncoder(EncoderFunc("application/x-msgpack", func(w io.Writer, v interface{}) os.Error {
+		_, err := w.Write([]byte{0x92, 0x01, 0x02})
+		return err
+	}))
+	failing := os.NewError("failed")
+	RegisterEncoder(EncoderFunc("text/plain; charset=utf-8", func(w io.Writer, v interface{}) os.Error {
+		return failing
+	}))
+	if len(encoders.list) != 4 {
+		t.Errorf("RegisterEncoder() gave %d encoders, not 4", len(encoders.list))
+	}
+	req := &http.Request{Header: http.Header{"Accept": {"application/x-msgpack"}}}
+	w := httptest.NewRecorder()
+	if err := Render(w, req, point{1, 2}); err != nil || w.Body.Len() != 3 {
+		t.Errorf("Render(application/x-msgpack) == %v, %v", err, w.Body.Bytes())
+	}
+	req = &http.Request{Header: http.Header{"Accept": {"text/plain"}}}
+	w = httptest.NewRecorder()
+	if err := Render(w, req, point{1, 2}); err != failing || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("Render() with a failing encoder == %v, %s", err, w.Body.String())
+	}
+}
+
+func TestNegotiatingWriter(t *testing.T) {
+	cond := []struct {
+		accept      string
+		handler     func(w http.ResponseWriter, req *http.Request)
+		code        int
+		contentType string
+		body        string
+	}{
+		{"application/json", func(w http.ResponseWriter, req *http.Request) {
+			w.(*NegotiatingWriter).SetValue(point{1, 2})
+			w.WriteHeader(http.StatusCreated)
+		}, http.StatusCreated, "application/json", `{"X":1,"Y":2}` + "\n"},
+		{"text/plain", func(w http.ResponseWriter, req *http.Request) {
+			w.(*NegotiatingWriter).SetValue(point{1, 2})
+		}, http.StatusOK, "text/plain;charset=utf-8", "(1, 2)"},
+		{"image/png", func(w http.ResponseWriter, req *http.Request) {
+			w.(*NegotiatingWriter).SetValue(point{1, 2})
+		}, http.StatusNotAcceptable, ApplicationProblemJSON, ""},
+		{"image/png", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		}, http.StatusOK, "image/png", "png"},
+	}
+	for _, c := range cond {
+		req := &http.Request{Header: http.Header{"Accept": {c.accept}}}
+		w := httptest.NewRecorder()
+		Negotiating(http.HandlerFunc(c.handler)).ServeHTTP(w, req)
+		if w.Code != c.code || w.Header().Get("Content-Type") != c.contentType || (c.body != "" && w.Body.String() != c.body) {
+			t.Errorf("Negotiating(%s) == %d, %v, %s", c.accept, w.Code, w.Header(), w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package mimeparse
+
+import (
+	"sort"
+	"strings"
+)
+
+// A set of media-ranges, such as the ranges of an Accept header or
+// the types a server is able to produce. Ranges that differ only in
+// their 'q' parameter are the same member of the set.
+type MediaRangeSet struct {
+	ranges []Mime
+}
+
+// Returns a set of the given media-ranges.
+func NewMediaRangeSet(ranges ...Mime) *MediaRangeSet {
+	s := &MediaRangeSet{}
+	for _, r := range ranges {
+		s.add(r)
+	}
+	return s
+}
+
+// Returns the set of the media-ranges in an Accept header.
+func ParseMediaRangeSet(header string) *MediaRangeSet {
+	return NewMediaRangeSet(ParseHeader(header)...)
+}
+
+// Returns the members of the set in the order they were added.
+func (s *MediaRangeSet) Ranges() []Mime {
+	ranges := make([]Mime, len(s.ranges))
+	copy(ranges, s.ranges)
+	return ranges
+}
+
+// Returns the members of the set formatted as an Accept header.
+func (s *MediaRangeSet) String() string {
+	parts := make([]string, len(s.ranges))
+	for i, r := range s.ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Reports whether the mime-type is acceptable to the set, that is
+// whether the most specific member covering it has a non-zero 'q'.
+func (s *MediaRangeSet) Contains(mime Mime) bool {
+	best, found := -1, false
+	var q float64
+	for _, r := range s.ranges {
+		if covers(r, mime) && rangeSpecificity(r) > best {
+			best, found, q = rangeSpecificity(r), true, r.Q()
+		}
+	}
+	return found && q > 0
+}
+
+// Returns a new set holding the members of both sets. A range in
+// both sets keeps the higher of its two 'q' values.
+func (s *MediaRangeSet) Union(other *MediaRangeSet) *MediaRangeSet {
+	u := NewMediaRangeSet(s.ranges...)
+	for _, r := range other.ranges {
+		u.add(r)
+	}
+	return u
+}
+
+// Returns a new set of the media-types acceptable to both sets. Each
+// pair of overlapping ranges contributes the narrower of the two,
+// e.g. 'text/*' and 'text/html' give 'text/html', with the lower
+// of the two 'q' values. Ranges with a 'q' of 0 never overlap.
+func (s *MediaRangeSet) Intersect(other *MediaRangeSet) *MediaRangeSet {
+	i := &MediaRangeSet{}
+	for _, a := range s.ranges {
+		for _, b := range other.ranges {
+			if r, ok := intersectRanges(a, b); ok {
+				i.add(r)
+			}
+		}
+	}
+	return i
+}
+
+// Adds a range, merging it with an existing member if they
+// differ only in 'q'.
+func (s *MediaRangeSet) add(r Mime) {
+	key := rangeKey(r)
+	for i, existing := range s.ranges {
+		if rangeKey(existing) == key {
+			if r.Q() > existing.Q() {
+				s.ranges[i] = r.MediaRange().mime
+			}
+			return
+		}
+	}
+	s.ranges = append(s.ranges, r.MediaRange().mime)
+}
+
+// Identifies a range by its type, subtype and ordinary parameters.
+func rangeKey(r Mime) string {
+	keys := r.ParamKeys()
+	sort.SortStrings(keys)
+	return formatMime(r, keys)
+}
+
+// Orders ranges by how narrow they are: '*/*' is 0, 'type/*' is 1,
+// 'type/subtype' is 2, and each ordinary parameter adds one more.
+func rangeSpecificity(r Mime) int {
+	s := 0
+	if r.mtype != "*" {
+		s++
+		if r.subtype != "*" {
+			s++
+		}
+	}
+	return s + len(r.ParamKeys())
+}
+
+// Reports whether every mime-type matched by 'm' is also
+// matched by the range 'r'.
+func covers(r, m Mime) bool {
+	if r.mtype != "*" && r.mtype != m.mtype {
+		return false
+	}
+	if r.subtype != "*" && r.subtype != m.subtype {
+		return false
+	}
+	for _, k := range r.ParamKeys() {
+		if value, ok := m.params.get(k); !ok || !defaultPolicy.paramEqual(k, value, r.params.value(k)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the range matching exactly the mime-types matched by both
+// 'a' and 'b', or false if there are none.
+func intersectRanges(a, b Mime) (Mime, bool) {
+	if a.Q() == 0 || b.Q() == 0 {
+		return Mime{}, false
+	}
+	r := Mime{}
+	switch {
+	case a.mtype == b.mtype || b.mtype == "*":
+		r.mtype = a.mtype
+	case a.mtype == "*":
+		r.mtype = b.mtype
+	default:
+		return Mime{}, false
+	}
+	switch {
+	case a.subtype == b.subtype || b.subtype == "*":
+		r.subtype = a.subtype
+	case a.subtype == "*":
+		r.subtype = b.subtype
+	default:
+		return Mime{}, false
+	}
+	if r.mtype == "*" && r.subtype != "*" {
+		return Mime{}, false
+	}
+	for _, m := range []Mime{a, b} {
+		for _, k := range m.ParamKeys() {
+			if value, ok := r.params.get(k); ok && !defaultPolicy.paramEqual(k, value, m.params.value(k)) {
+				return Mime{}, false
+			}
+			r.setParam(k, m.params.value(k))
+		}
+	}
+	r.SetQ(a.Q())
+	if b.Q() < a.Q() {
+		r.SetQ(b.Q())
+	}
+	return r, true
+}
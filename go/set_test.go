@@ -0,0 +1,63 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func mustParse(t *testing.T, mime string) Mime {
+	m, err := ParseMediaRange(mime)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", mime, err)
+	}
+	return m
+}
+
+func TestMediaRangeSetContains(t *testing.T) {
+	s := ParseMediaRangeSet("text/*;q=0.5, text/html;q=0, application/json, image/png;level=1")
+	cond := map[string]bool{
+		"text/plain":        true,
+		"text/html":         false,
+		"text/html;level=1": false,
+		"application/json":  true,
+		"application/xml":   false,
+		"image/png":         false,
+		"image/png;level=1": true,
+		"text/*":            true,
+		"*/*":               false,
+	}
+	for mime, want := range cond {
+		if got := s.Contains(mustParse(t, mime)); got != want {
+			t.Errorf("Contains(%s) == %v, not %v", mime, got, want)
+		}
+	}
+}
+
+func TestMediaRangeSetUnion(t *testing.T) {
+	a := ParseMediaRangeSet("text/html;q=0.5, application/json")
+	b := ParseMediaRangeSet("text/html;q=0.8, image/png;q=0.1")
+	u := a.Union(b)
+	if s := u.String(); s != "text/html;q=0.8, application/json;q=1, image/png;q=0.1" {
+		t.Errorf("Union == %s", s)
+	}
+	if a.String() != "text/html;q=0.5, application/json;q=1" {
+		t.Errorf("Union modified its receiver: %s", a.String())
+	}
+}
+
+func TestMediaRangeSetIntersect(t *testing.T) {
+	cond := []struct{ a, b, result string }{
+		{"text/*, application/json", "text/html;q=0.5, image/png", "text/html;q=0.5"},
+		{"*/*;q=0.8", "application/json, text/plain;q=0.3", "application/json;q=0.8, text/plain;q=0.3"},
+		{"text/html;level=1", "text/*;charset=utf-8", "text/html;level=1;charset=utf-8;q=1"},
+		{"text/html;level=1", "text/html;level=2", ""},
+		{"text/html;q=0", "text/*", ""},
+		{"image/*", "text/*", ""},
+		{"text/html, text/*", "text/html", "text/html;q=1"},
+	}
+	for _, c := range cond {
+		i := ParseMediaRangeSet(c.a).Intersect(ParseMediaRangeSet(c.b))
+		if i.String() != c.result {
+			t.Errorf("Intersect(%s, %s) == %s, not %s", c.a, c.b, i.String(), c.result)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package mimeparse
+
+import (
+	"http"
+)
+
+// Decides what a payload is and whether a client accepts it, for
+// download endpoints and upload proxies that do not know the type
+// of what they pass on. The type is sniffed from the first 512 bytes
+// of 'data' with http.DetectContentType(), which falls back to
+// 'application/octet-stream', and accepted when it has a quality
+// above 0 under the Accept header 'header'. As with
+// NegotiateRequest() an empty header accepts anything. For example:
+//
+// SniffAccepted([]byte("\x89PNG\r\n\x1a\n..."), "image/*")
+// Mime {'image', 'png', {}}, true
+func SniffAccepted(data []byte, header string) (mime Mime, accepted bool) {
+	return defaultPolicy.SniffAccepted(data, header)
+}
+
+// Like SniffAccepted() but applying the options of the policy.
+func (p *Policy) SniffAccepted(data []byte, header string) (mime Mime, accepted bool) {
+	if len(data) > 512 {
+		data = data[:512]
+	}
+	mime, err := p.ParseMimeType(http.DetectContentType(data))
+	if err != nil {
+		return mime, false
+	}
+	if header == "" {
+		header = "*/*"
+	}
+	return mime, p.Quality(mime.String(), header) > 0
+}
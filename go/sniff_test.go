@@ -0,0 +1,32 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestSniffAccepted(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	html := []byte("<!DOCTYPE html><html><body>hi</body></html>")
+	cond := []struct {
+		data     []byte
+		header   string
+		mime     string
+		accepted bool
+	}{
+		{png, "image/*", "image/png", true},
+		{png, "image/png, */*;q=0", "image/png", true},
+		{png, "text/html", "image/png", false},
+		{png, "", "image/png", true},
+		{html, "text/html", "text/html;charset=utf-8", true},
+		{html, "text/html;charset=iso-8859-1", "text/html;charset=utf-8", false},
+		{html, "application/json", "text/html;charset=utf-8", false},
+		{[]byte{0, 1, 2, 3}, "*/*", "application/octet-stream", true},
+		{[]byte{0, 1, 2, 3}, "image/*", "application/octet-stream", false},
+	}
+	for _, c := range cond {
+		mime, accepted := SniffAccepted(c.data, c.header)
+		if mime.String() != c.mime || accepted != c.accepted {
+			t.Errorf("SniffAccepted(%q, %s) == %s, %v, not %s, %v", c.data, c.header, mime, accepted, c.mime, c.accepted)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mimeparse
+
+import (
+	"expvar"
+	"json"
+	"sync"
+)
+
+// Counts the outcomes of negotiations. Set it as Policy.Observer to
+// collect them, then read the counters with Snapshot(), for example to
+// feed Prometheus collectors, or publish them with Publish(). A Stats
+// may be shared by many policies and goroutines.
+type Stats struct {
+	mutex         sync.Mutex
+	total         int64
+	notAcceptable int64
+	types         map[string]int64
+	specificity   map[Specificity]int64
+}
+
+// The counters of a Stats at one point in time.
+type StatsSnapshot struct {
+	Total         int64                 // negotiations
+	NotAcceptable int64                 // negotiations that found no acceptable type
+	Types         map[string]int64      // chosen supported types
+	Specificity   map[Specificity]int64 // how the chosen types were selected
+}
+
+// Returns a Stats with all counters at zero.
+func NewStats() *Stats {
+	return &Stats{types: make(map[string]int64), specificity: make(map[Specificity]int64)}
+}
+
+// Counts a negotiation, see Observer.
+func (s *Stats) OnNegotiate(m Match, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.total++
+	if !ok {
+		s.notAcceptable++
+		return
+	}
+	s.types[m.Type]++
+	s.specificity[m.Specificity]++
+}
+
+// Returns a copy of the counters.
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snapshot := StatsSnapshot{s.total, s.notAcceptable, make(map[string]int64), make(map[Specificity]int64)}
+	for k, v := range s.types {
+		snapshot.Types[k] = v
+	}
+	for k, v := range s.specificity {
+		snapshot.Specificity[k] = v
+	}
+	return snapshot
+}
+
+// Formats the counters as a JSON object, which makes a Stats an
+// expvar.Var.
+func (s *Stats) String() string {
+	snapshot := s.Snapshot()
+	specificity := make(map[string]int64)
+	for k, v := range snapshot.Specificity {
+		specificity[k.String()] = v
+	}
+	b, _ := json.Marshal(map[string]interface{}{
+		"total":          snapshot.Total,
+		"not_acceptable": snapshot.NotAcceptable,
+		"types":          snapshot.Types,
+		"specificity":    specificity,
+	})
+	return string(b)
+}
+
+// Publishes the counters with expvar under 'name', so they show up
+// at /debug/vars. Like expvar.Publish() it panics if the name is
+// already in use.
+func (s *Stats) Publish(name string) {
+	expvar.Publish(name, s)
+}
@@ -0,0 +1,39 @@
+package mimeparse
+
+import (
+	"expvar"
+	"json"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	stats := NewStats()
+	p := &Policy{Observer: stats}
+	supported := []string{"application/json", "text/html"}
+	for _, header := range []string{"text/html", "*/*", "image/png", "application/*", "*/*"} {
+		p.BestMatch(supported, header)
+	}
+	s := stats.Snapshot()
+	if s.Total != 5 || s.NotAcceptable != 1 || s.Types["application/json"] != 3 || s.Types["text/html"] != 1 ||
+		s.Specificity[AnyMatch] != 2 || s.Specificity[TypeMatch] != 1 || s.Specificity[ExactMatch] != 1 {
+		t.Errorf("Snapshot() == %v", s)
+	}
+	var published map[string]interface{}
+	if err := json.Unmarshal([]byte(stats.String()), &published); err != nil {
+		t.Fatalf("String() == %s: %v", stats.String(), err)
+	}
+	if published["total"] != float64(5) || published["specificity"].(map[string]interface{})["any"] != float64(2) {
+		t.Errorf("String() == %v", published)
+	}
+}
+
+func TestStatsPublish(t *testing.T) {
+	// expvar refuses a name published before, as it is when the
+	// tests run more than once in a process.
+	if expvar.Get("mimeparse_test") == nil {
+		NewStats().Publish("mimeparse_test")
+	}
+	if _, ok := expvar.Get("mimeparse_test").(*Stats); !ok {
+		t.Errorf("Publish() did not publish the stats")
+	}
+}
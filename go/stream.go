@@ -0,0 +1,46 @@
+package mimeparse
+
+// Media types of responses that are streamed rather than sent as
+// a single document.
+const (
+	TextEventStream        = "text/event-stream"
+	ApplicationNDJSON      = "application/x-ndjson"
+	ApplicationJSONSeq     = "application/json-seq"
+	ApplicationStreamJSON  = "application/stream+json"
+	MultipartXMixedReplace = "multipart/x-mixed-replace"
+)
+
+// The streaming types, keyed by type/subtype.
+var streamingTypes = map[string]bool{
+	TextEventStream:        true,
+	ApplicationNDJSON:      true,
+	"application/ndjson":   true,
+	ApplicationJSONSeq:     true,
+	ApplicationStreamJSON:  true,
+	MultipartXMixedReplace: true,
+}
+
+// Reports whether the mime-type is one of the streaming types, such
+// as 'text/event-stream' or 'application/x-ndjson'. Streaming types
+// are never selected by a structured syntax suffix, so neither
+// 'application/*+json' nor 'application/json' with Policy.SuffixMatch
+// accepts 'application/stream+json'. They are selected by ranges that
+// name them and by plain wildcards, which lets one supported list
+// offer both a buffered document and a stream:
+//
+// BestMatch(['application/json', 'text/event-stream'], 'text/event-stream')
+// 'text/event-stream'
+// BestMatch(['application/json', 'text/event-stream'], '*/*')
+// 'application/json'
+func (m Mime) IsStreaming() bool {
+	return streamingTypes[m.mtype+"/"+m.subtype]
+}
+
+// Reports whether the header accepts one of the streaming types more
+// than any of the other supported types, for handlers that decide
+// whether to stream before choosing the exact type.
+func PrefersStream(supported []string, header string) bool {
+	mime := BestMatch(supported, header)
+	m, err := ParseMimeType(mime)
+	return err == nil && m.IsStreaming()
+}
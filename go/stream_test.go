@@ -0,0 +1,39 @@
+package mimeparse
+
+import (
+	"testing"
+)
+
+func TestIsStreaming(t *testing.T) {
+	cond := map[string]bool{
+		"text/event-stream":                     true,
+		"application/x-ndjson":                  true,
+		"application/stream+json;charset=utf-8": true,
+		"multipart/x-mixed-replace;boundary=x":  true,
+		"application/json":                      false,
+		"text/*":                                false,
+	}
+	for in, out := range cond {
+		if m, _ := ParseMimeType(in); m.IsStreaming() != out {
+			t.Errorf("IsStreaming(%s) == %v, not %v", in, !out, out)
+		}
+	}
+}
+
+func TestStreamingMatching(t *testing.T) {
+	supported := []string{ApplicationJSON, TextEventStream, ApplicationNDJSON, ApplicationStreamJSON}
+	bestMatch(t, supported, map[string]string{
+		"text/event-stream":                            TextEventStream,
+		"application/x-ndjson, application/json;q=0.5": ApplicationNDJSON,
+		"*/*":                     ApplicationJSON,
+		"application/*+json":      "",
+		"application/stream+json": ApplicationStreamJSON,
+		"text/*":                  TextEventStream,
+	})
+	if got := (&Policy{SuffixMatch: true}).BestMatch([]string{ApplicationStreamJSON}, "application/json"); got != "" {
+		t.Errorf("SuffixMatch BestMatch(application/json) == %s", got)
+	}
+	if !PrefersStream(supported, "text/event-stream, application/json;q=0.1") || PrefersStream(supported, "application/json") {
+		t.Errorf("PrefersStream() wrong")
+	}
+}
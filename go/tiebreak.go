@@ -0,0 +1,44 @@
+package mimeparse
+
+// Chooses between supported types that a header accepts with the same
+// quality, see Policy.TieBreaker. It must order matches consistently:
+// if it prefers 'a' over 'b' it may not prefer 'b' over 'a'. When it
+// prefers neither the order of the supported types decides.
+type TieBreaker interface {
+	// Reports whether 'a' should be chosen over 'b'.
+	Prefer(a, b Match) bool
+}
+
+// Adapts a function to the TieBreaker interface.
+type TieBreakerFunc func(a, b Match) bool
+
+func (f TieBreakerFunc) Prefer(a, b Match) bool {
+	return f(a, b)
+}
+
+// Built-in tie-breakers.
+var (
+	// Prefers the type listed first in the supported types, which is
+	// also what happens without a tie-breaker.
+	ServerOrderTie TieBreaker = TieBreakerFunc(func(a, b Match) bool {
+		return a.index < b.index
+	})
+	// Prefers the type whose media-range comes first in the header,
+	// like Policy.ClientOrder.
+	ClientOrderTie TieBreaker = TieBreakerFunc(func(a, b Match) bool {
+		return a.rangeIndex < b.rangeIndex
+	})
+	// Prefers the type selected by the more specific media-range, see
+	// Specificity, and among those by the range with more parameters.
+	MostSpecificTie TieBreaker = TieBreakerFunc(func(a, b Match) bool {
+		if a.Specificity != b.Specificity {
+			return a.Specificity > b.Specificity
+		}
+		return a.Params > b.Params
+	})
+	// Prefers the type that sorts first by name, which keeps results
+	// stable when the supported types come from an unordered source.
+	AlphabeticalTie TieBreaker = TieBreakerFunc(func(a, b Match) bool {
+		return a.Type < b.Type
+	})
+)
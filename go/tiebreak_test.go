@@ -0,0 +1,32 @@
+package mimeparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTieBreaker(t *testing.T) {
+	supported := []string{"text/plain", "text/html", "application/json"}
+	header := "application/json, text/*, text/html"
+	cond := []struct {
+		tie  TieBreaker
+		best string
+		all  []string
+	}{
+		{nil, "text/plain", []string{"text/html", "application/json", "text/plain"}},
+		{ServerOrderTie, "text/plain", []string{"text/plain", "text/html", "application/json"}},
+		{ClientOrderTie, "application/json", []string{"application/json", "text/plain", "text/html"}},
+		{MostSpecificTie, "text/html", []string{"text/html", "application/json", "text/plain"}},
+		{AlphabeticalTie, "application/json", []string{"application/json", "text/html", "text/plain"}},
+		{TieBreakerFunc(func(a, b Match) bool { return a.Index() > b.Index() }), "application/json", []string{"application/json", "text/html", "text/plain"}},
+	}
+	for _, c := range cond {
+		p := &Policy{TieBreaker: c.tie}
+		if got := p.BestMatch(supported, header); got != c.best {
+			t.Errorf("BestMatch() with %v == %s, not %s", c.tie, got, c.best)
+		}
+		if got := matchTypes(p.AllMatches(supported, header)); !reflect.DeepEqual(got, c.all) {
+			t.Errorf("AllMatches() with %v == %v, not %v", c.tie, got, c.all)
+		}
+	}
+}
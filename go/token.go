@@ -0,0 +1,264 @@
+package mimeparse
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Returned when an input contains a control character, such as a
+// CR or LF that could inject a header, see checkControl().
+var ErrControlChar = os.NewError("Control character in input")
+
+// Returns ErrControlChar if 's' contains a control character other
+// than tab, which may only appear as whitespace.
+func checkControl(s string) os.Error {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < ' ' && s[i] != '\t') || s[i] == 0x7f {
+			return ErrControlChar
+		}
+	}
+	return nil
+}
+
+// Joins the lines of a folded mail header, see RFC 5322 section 2.2.3.
+func unfold(s string) string {
+	s = strings.Replace(s, "\r\n ", " ", -1)
+	return strings.Replace(s, "\r\n\t", "\t", -1)
+}
+
+// Replaces every comment in 's', text in parentheses outside of
+// quoted-strings, with a space. Comments may nest, and within them
+// a backslash escapes the next character. An unterminated comment
+// runs to the end of 's'.
+func stripComments(s string) string {
+	b := new(bytes.Buffer)
+	depth := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case depth > 0 && c == '\\':
+			i++
+		case depth > 0 && c == '(':
+			depth++
+		case depth > 0 && c == ')':
+			if depth--; depth == 0 {
+				b.WriteByte(' ')
+			}
+		case depth > 0:
+		case quoted && c == '\\':
+			b.WriteByte(c)
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case c == '"':
+			quoted = !quoted
+			b.WriteByte(c)
+		case !quoted && c == '(':
+			depth++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Splits 's' at every 'sep' that is not inside a quoted-string.
+// Within a quoted-string a backslash escapes the next character.
+func splitQuoted(s string, sep byte) []string {
+	parts := make([]string, 0, countQuoted(s, sep))
+	start := 0
+	for end := nextQuoted(s, 0, sep); end < len(s); end = nextQuoted(s, start, sep) {
+		parts = append(parts, s[start:end])
+		start = end + 1
+	}
+	return append(parts, s[start:])
+}
+
+// Returns the index of the first 'sep' at or after 'start' that is
+// not inside a quoted-string, or len(s) if there is none. Scanning
+// from the start of 's' or just after such a separator, which is
+// never quoted, lets callers walk the parts of 's' without
+// splitting it.
+func nextQuoted(s string, start int, sep byte) int {
+	quoted := false
+	for i := start; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			return i
+		}
+	}
+	return len(s)
+}
+
+// Returns the number of parts splitQuoted() would split 's' into.
+func countQuoted(s string, sep byte) int {
+	n := 1
+	for i := nextQuoted(s, 0, sep); i < len(s); i = nextQuoted(s, i+1, sep) {
+		n++
+	}
+	return n
+}
+
+// Lower-cases the ASCII letters of 's', returning 's' itself, without
+// allocating, when it has none in upper case.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if 'A' <= s[i] && s[i] <= 'Z' {
+			return strings.ToLower(s)
+		}
+	}
+	return s
+}
+
+// Returns the value of a parameter, removing the quotes and
+// backslash escapes of a quoted-string. An unterminated
+// quoted-string runs to the end of the value.
+func unquote(s string) string {
+	if len(s) == 0 || s[0] != '"' {
+		return s
+	}
+	b := new(bytes.Buffer)
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Reports whether 'c' may appear in a token, see RFC 7230 section 3.2.6.
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return c != 0 && bytes.IndexByte([]byte("!#$%&'*+-.^_`|~"), c) >= 0
+}
+
+// Reports whether 's' is a non-empty token.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the longest prefix of 's' that consists of token
+// characters, so that 'html<script>' gives 'html'.
+func tokenPrefix(s string) string {
+	for i := 0; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// Returns 's' unchanged if it is a token, otherwise as a
+// quoted-string with '"' and '\' escaped.
+func quoteIfNeeded(s string) string {
+	if isToken(s) {
+		return s
+	}
+	b := new(bytes.Buffer)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Reports whether 's' follows the qvalue syntax of RFC 7231 section
+// 5.3.1, a 0 or 1 with at most three decimals, where a 1 may only be
+// followed by zeros.
+func isQValue(s string) bool {
+	if s == "" || (s[0] != '0' && s[0] != '1') {
+		return false
+	}
+	if len(s) == 1 {
+		return true
+	}
+	if s[1] != '.' || len(s) > 5 {
+		return false
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' || (s[0] == '1' && s[i] != '0') {
+			return false
+		}
+	}
+	return true
+}
+
+// An element of a comma separated header such as Accept-Encoding,
+// TE or Accept-Charset: a case-insensitive value with optional
+// parameters and a weight.
+type weighted struct {
+	value  string
+	params map[string]string
+	q      float
+}
+
+// Parses a header of weighted values. Empty elements are skipped,
+// and a malformed or out of range 'q' counts as 1 as it does in a
+// media-range. A header with a control character other than tab or
+// exceeding DefaultMaxLength or DefaultMaxRanges gives no values.
+func parseWeighted(header string) []weighted {
+	list := []weighted{}
+	if checkControl(header) != nil || exceeds(len(header), 0, DefaultMaxLength) {
+		return list
+	}
+	elements := splitQuoted(header, ',')
+	if exceeds(len(elements), 0, DefaultMaxRanges) {
+		return list
+	}
+	for _, element := range elements {
+		value, parts := ht(splitQuoted(element, ';'))
+		w := weighted{strings.ToLower(strings.TrimSpace(value)), make(map[string]string), 1}
+		if w.value == "" {
+			continue
+		}
+		for _, part := range parts {
+			kv := strings.Split(part, "=", 2)
+			key := strings.ToLower(strings.TrimSpace(kv[0]))
+			if key == "" {
+				continue
+			}
+			if len(kv) == 2 {
+				w.params[key] = unquote(strings.TrimSpace(kv[1]))
+			} else {
+				w.params[key] = ""
+			}
+		}
+		if q, ok := w.params["q"]; ok {
+			if val, err := strconv.Atof(q); err == nil && val >= 0 && val <= 1 {
+				w.q = val
+			}
+		}
+		list = append(list, w)
+	}
+	return list
+}
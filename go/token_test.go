@@ -0,0 +1,99 @@
+package mimeparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitQuoted(t *testing.T) {
+	cond := map[string][]string{
+		`a;b;c`:       {"a", "b", "c"},
+		`a;"b;c";d`:   {"a", `"b;c"`, "d"},
+		`a;"b\";c";d`: {"a", `"b\";c"`, "d"},
+		`a;"b;c`:      {"a", `"b;c`},
+		``:            {""},
+		`;`:           {"", ""},
+		`x="\\";y`:    {`x="\\"`, "y"},
+	}
+	for in, out := range cond {
+		if got := splitQuoted(in, ';'); !reflect.DeepEqual(got, out) {
+			t.Errorf("splitQuoted(%s) == %#v, not %#v", in, got, out)
+		}
+	}
+}
+
+func TestQuoting(t *testing.T) {
+	cond := map[string]string{
+		"utf-8":    "utf-8",
+		"":         `""`,
+		"a b":      `"a b"`,
+		`say "hi"`: `"say \"hi\""`,
+		`back\`:    `"back\\"`,
+		"a;b,c":    `"a;b,c"`,
+	}
+	for in, out := range cond {
+		if got := quoteIfNeeded(in); got != out {
+			t.Errorf("quoteIfNeeded(%s) == %s, not %s", in, got, out)
+		}
+		if got := unquote(out); got != in {
+			t.Errorf("unquote(%s) == %s, not %s", out, got, in)
+		}
+	}
+}
+
+func TestIsQValue(t *testing.T) {
+	cond := map[string]bool{
+		"0": true, "1": true, "0.": true, "0.5": true, "0.123": true, "1.000": true,
+		"": false, "0.1234": false, "1.5": false, ".5": false, "2": false, "0.5e0": false, "00.5": false,
+	}
+	for in, out := range cond {
+		if got := isQValue(in); got != out {
+			t.Errorf("isQValue(%s) == %v, not %v", in, got, out)
+		}
+	}
+}
+
+func TestControlChars(t *testing.T) {
+	for _, in := range []string{"text/html\r\nSet-Cookie: a=b", "text/html\n", "text/html;a=\"\x00\"", "text/\x7fhtml"} {
+		if _, err := ParseMimeType(in); err != ErrControlChar {
+			t.Errorf("ParseMimeType(%q) error == %v", in, err)
+		}
+		if _, err := ParseContentDisposition("attachment; filename=" + in); err != ErrControlChar {
+			t.Errorf("ParseContentDisposition(%q) error == %v", in, err)
+		}
+		if got := BestMatch([]string{"text/html"}, "text/*, "+in); got != "" {
+			t.Errorf("BestMatch(%q) == %s", in, got)
+		}
+		if got := BestCharset([]string{"utf-8"}, "utf-8, "+in); got != "" {
+			t.Errorf("BestCharset(%q) == %s", in, got)
+		}
+	}
+	if _, err := ParseMimeType("text/html;\ta=1"); err != nil {
+		t.Errorf("ParseMimeType() with a tab failed: %v", err)
+	}
+}
+
+func TestMailSyntax(t *testing.T) {
+	p := &Policy{MailSyntax: true}
+	cond := map[string]string{
+		"text/plain; charset=us-ascii (Plain text)":          "text/plain;charset=us-ascii",
+		"text/plain (plain text); charset=\"us-ascii\"":      "text/plain;charset=us-ascii",
+		"text/(a (nested) comment)plain":                     "text/plain",
+		"text/plain; name=\"a (not a comment).txt\"":         "text/plain;name=\"a (not a comment).txt\"",
+		"text/plain; (a \\) in a comment) name=x":            "text/plain;name=x",
+		"multipart/mixed;\r\n\tboundary=\"simple boundary\"": "multipart/mixed;boundary=\"simple boundary\"",
+		"text/plain; name=\"a\\\"(b\"":                       "text/plain;name=\"a\\\"(b\"",
+	}
+	for in, out := range cond {
+		m, err := p.ParseMimeType(in)
+		if err != nil || m.String() != out {
+			t.Errorf("ParseMimeType(%q) == %s, %v, not %s", in, m, err, out)
+		}
+	}
+	if m, _ := ParseMimeType("text/plain; charset=us-ascii (Plain text)"); m.Params()["charset"] == "us-ascii" {
+		t.Errorf("Comments should only be removed when the policy asks for it")
+	}
+	if _, err := p.ParseMimeType("text/plain;\r\nname=x"); err != ErrControlChar {
+		t.Errorf("ParseMimeType() with a bare CR LF error == %v", err)
+	}
+}